@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/spf13/cobra"
+)
+
+// defaultLintTypes are the commit types of the Conventional Commits
+// specification and the Angular convention.
+var defaultLintTypes = []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+// scissorsLine marks the start of the diff that git appends to the message
+// of a verbose commit, everything below it is ignored.
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+func newLintCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "check that the commit messages of a range can be analyzed",
+		Args:  cobra.NoArgs,
+		Run:   lintHandler,
+	}
+	cmd.Flags().String("from", "", "exclusive start of the range (default: the latest release)")
+	cmd.Flags().String("to", "", "inclusive end of the range (default: the current commit)")
+	cmd.Flags().Bool("stdin", false, "check a single commit message read from stdin")
+	cmd.Flags().String("message-file", "", "check the commit message of a file, e.g. in a commit-msg git hook")
+	cmd.Flags().StringSlice("types", defaultLintTypes, "allowed commit types")
+	cmd.Flags().Bool("require-scope", false, "report commits without a scope")
+	return cmd
+}
+
+// lintRules are the checks that every analyzed commit has to pass.
+type lintRules struct {
+	types        map[string]bool
+	requireScope bool
+}
+
+type lintIssue struct {
+	SHA     string `json:"sha,omitempty"`
+	Header  string `json:"header"`
+	Problem string `json:"problem"`
+}
+
+type lintReport struct {
+	Commits int          `json:"commits"`
+	Issues  []*lintIssue `json:"issues"`
+}
+
+// lintCommits returns the problems of the analyzed commits. Merge commits and
+// reverts created by git are not expected to follow the convention.
+func lintCommits(commits []*semrel.Commit, rules *lintRules) []*lintIssue {
+	issues := make([]*lintIssue, 0)
+	for _, c := range commits {
+		if len(c.Parents) > 1 || c.Annotations["reverts"] != "" {
+			continue
+		}
+		header := ""
+		if len(c.Raw) > 0 {
+			header = c.Raw[0]
+		}
+		issue := &lintIssue{SHA: c.SHA, Header: header}
+		switch {
+		case c.Type == "":
+			issue.Problem = "does not follow the commit convention"
+		case !rules.types[c.Type]:
+			issue.Problem = fmt.Sprintf("unknown type %s", c.Type)
+		case rules.requireScope && c.Scope == "":
+			issue.Problem = "missing scope"
+		default:
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// parseCommitMessage removes the comments and the diff that git adds to the
+// message of a commit that is being edited.
+func parseCommitMessage(message string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(message, "\n") {
+		if line == scissorsLine {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (l *lintReport) write(w io.Writer, output string) error {
+	if output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(l)
+	}
+	sb := &strings.Builder{}
+	for _, issue := range l.Issues {
+		if issue.SHA != "" {
+			fmt.Fprintf(sb, "%s ", shortSHA(issue.SHA))
+		}
+		fmt.Fprintf(sb, "%q: %s\n", issue.Header, issue.Problem)
+	}
+	fmt.Fprintf(sb, "%d commits checked, %d problems found\n", l.Commits, len(l.Issues))
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func lintHandler(cmd *cobra.Command, args []string) {
+	logger := log.New(os.Stderr, "[go-semantic-release]: ", 0)
+	exitIfError := errorHandler(logger)
+
+	types, err := cmd.Flags().GetStringSlice("types")
+	exitIfError(err)
+	rules := &lintRules{types: make(map[string]bool)}
+	for _, t := range types {
+		rules.types[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	rules.requireScope, err = cmd.Flags().GetBool("require-scope")
+	exitIfError(err)
+
+	var analyzer plugin.CommitAnalyzer
+	var rawCommits []*semrel.RawCommit
+	var output string
+	if message, ok := readLintMessage(cmd, exitIfError); ok {
+		// a single message is checked without the provider, e.g. in a git hook
+		conf, err := config.NewConfig(cmd)
+		exitIfError(err)
+		pluginManager, err := plugin.NewManager(conf)
+		exitIfError(err)
+		exitHandler = func(error) { pluginManager.Stop() }
+		defer pluginManager.Stop()
+		analyzer, err = pluginManager.GetCommitAnalyzer()
+		exitIfError(err)
+		exitIfError(analyzer.Init(conf.CommitAnalyzerOpts))
+		rawCommits = []*semrel.RawCommit{{RawMessage: message}}
+		output = conf.Output
+	} else {
+		// only the provider and the commit analyzer are needed, the run
+		// result is not printed by the lint command
+		r := loadReleaser(cmd, logger, exitIfError)
+		exitHandler = func(error) { r.pluginManager.Stop() }
+		defer r.pluginManager.Stop()
+		r.initProvider()
+		r.initCommitAnalyzer()
+		analyzer, output = r.commitAnalyzer, r.conf.Output
+		rawCommits = r.lintRange(cmd)
+	}
+
+	report := &lintReport{Commits: len(rawCommits), Issues: lintCommits(analyzer.Analyze(rawCommits), rules)}
+	exitIfError(report.write(os.Stdout, output))
+	if len(report.Issues) > 0 {
+		exitIfError(fmt.Errorf("found %d invalid commit messages", len(report.Issues)))
+	}
+}
+
+// readLintMessage returns the commit message of the --stdin or --message-file
+// flag if one of them is used.
+func readLintMessage(cmd *cobra.Command, exitIfError func(error, ...int)) (string, bool) {
+	useStdin, err := cmd.Flags().GetBool("stdin")
+	exitIfError(err)
+	messageFile, err := cmd.Flags().GetString("message-file")
+	exitIfError(err)
+	var data []byte
+	switch {
+	case useStdin:
+		data, err = ioutil.ReadAll(os.Stdin)
+	case messageFile != "":
+		data, err = ioutil.ReadFile(messageFile)
+	default:
+		return "", false
+	}
+	exitIfError(err)
+	return parseCommitMessage(string(data)), true
+}
+
+// lintRange returns the commits between --from and --to. Without --from the
+// commits since the latest release of every package are returned, only then
+// the release branch is resolved.
+func (r *releaser) lintRange(cmd *cobra.Command) []*semrel.RawCommit {
+	logger, exitIfError := r.logger, r.exitIfError
+	from, err := cmd.Flags().GetString("from")
+	exitIfError(err)
+	to, err := cmd.Flags().GetString("to")
+	exitIfError(err)
+	if to == "" {
+		to = r.ci.GetCurrentSHA()
+	}
+
+	fromShas := []string{from}
+	if from == "" {
+		r.initBranch()
+		fromShas = make([]string, 0)
+		fetched := make(map[string]bool)
+		for _, pkg := range r.packages() {
+			tagFormat, err := semrel.NewTagFormat(pkg.TagTemplate())
+			exitIfError(err)
+			releases, err := r.prov.GetReleases(r.releaseRegex(pkg, tagFormat))
+			exitIfError(err)
+			release, err := semrel.GetLatestBranchRelease(r.conf, releases, r.branch)
+			exitIfError(err)
+			if !fetched[release.SHA] {
+				fetched[release.SHA] = true
+				fromShas = append(fromShas, release.SHA)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	commits := make([]*semrel.RawCommit, 0)
+	for _, from := range fromShas {
+		if from == "" {
+			logger.Printf("getting all commits up to %s...", shortSHA(to))
+		} else {
+			logger.Printf("getting commits from %s to %s...", shortSHA(from), shortSHA(to))
+		}
+		rawCommits, err := r.prov.GetCommits(from, to)
+		exitIfError(err)
+		for _, c := range rawCommits {
+			if !seen[c.SHA] {
+				seen[c.SHA] = true
+				commits = append(commits, c)
+			}
+		}
+	}
+	return commits
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/duanqy/semantic-release/plugin/commit_analyzer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCommits(t *testing.T) {
+	analyzer := &commit_analyzer.DefaultCommitAnalyzer{}
+	require.NoError(t, analyzer.Init(map[string]string{}))
+	commits := analyzer.Analyze([]*semrel.RawCommit{
+		{SHA: "a", RawMessage: "feat(api): add search"},
+		{SHA: "b", RawMessage: "fix: typo"},
+		{SHA: "c", RawMessage: "added some stuff"},
+		{SHA: "d", RawMessage: "feature(api): add filters"},
+		{SHA: "e", RawMessage: "Merge branch 'main'", Parents: []string{"a", "b"}},
+		{SHA: "f", RawMessage: "Revert \"added some stuff\"\n\nThis reverts commit 1234567."},
+	})
+
+	rules := &lintRules{types: map[string]bool{"feat": true, "fix": true}}
+	require.Equal(t, []*lintIssue{
+		{SHA: "c", Header: "added some stuff", Problem: "does not follow the commit convention"},
+		{SHA: "d", Header: "feature(api): add filters", Problem: "unknown type feature"},
+	}, lintCommits(commits, rules))
+
+	rules.requireScope = true
+	issues := lintCommits(commits, rules)
+	require.Len(t, issues, 3)
+	require.Equal(t, &lintIssue{SHA: "b", Header: "fix: typo", Problem: "missing scope"}, issues[0])
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, (&lintReport{Commits: len(commits), Issues: issues[:1]}).write(buf, "text"))
+	require.Equal(t, "b \"fix: typo\": missing scope\n6 commits checked, 1 problems found\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, (&lintReport{Commits: 1, Issues: []*lintIssue{}}).write(buf, "json"))
+	require.JSONEq(t, `{"commits": 1, "issues": []}`, buf.String())
+}
+
+func TestParseCommitMessage(t *testing.T) {
+	message := `feat: add search
+
+details
+# Please enter the commit message for your changes.
+#
+# ------------------------ >8 ------------------------
+diff --git a/main.go b/main.go
+`
+	require.Equal(t, "feat: add search\n\ndetails", parseCommitMessage(message))
+}
@@ -3,25 +3,24 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 
-	"github.com/Masterminds/semver/v3"
 	"github.com/duanqy/semantic-release/pkg/config"
 	"github.com/duanqy/semantic-release/pkg/plugin"
-	"github.com/duanqy/semantic-release/pkg/semrel"
 	"github.com/spf13/cobra"
 
 	_ "github.com/duanqy/semantic-release/plugin/changelog_generator"
 	_ "github.com/duanqy/semantic-release/plugin/commit_analyzer"
+	_ "github.com/duanqy/semantic-release/plugin/condition/azuredevops_condition"
+	_ "github.com/duanqy/semantic-release/plugin/condition/bitbucket_condition"
 	_ "github.com/duanqy/semantic-release/plugin/condition/default_condition"
 	_ "github.com/duanqy/semantic-release/plugin/condition/github_condition"
 	_ "github.com/duanqy/semantic-release/plugin/condition/gitlab_condition"
 	_ "github.com/duanqy/semantic-release/plugin/files_updater_npm"
+	_ "github.com/duanqy/semantic-release/plugin/provider/azuredevopsprovider"
+	_ "github.com/duanqy/semantic-release/plugin/provider/bitbucketprovider"
+	_ "github.com/duanqy/semantic-release/plugin/provider/giteaprovider"
 	_ "github.com/duanqy/semantic-release/plugin/provider/githubprovider"
 	_ "github.com/duanqy/semantic-release/plugin/provider/gitlabprovider"
 	_ "github.com/duanqy/semantic-release/plugin/provider/gitprovider"
@@ -30,14 +29,14 @@ import (
 // SRVERSION is the semantic-release version (added at compile time)
 var SRVERSION string = "dqy"
 
-var exitHandler func()
+var exitHandler func(error)
 
 func errorHandler(logger *log.Logger) func(error, ...int) {
 	return func(err error, exitCode ...int) {
 		if err != nil {
 			logger.Println(err)
 			if exitHandler != nil {
-				exitHandler()
+				exitHandler(err)
 			}
 			if len(exitCode) == 1 {
 				os.Exit(exitCode[0])
@@ -56,6 +55,14 @@ func main() {
 		Version: SRVERSION,
 	}
 
+	cmd.AddCommand(&cobra.Command{
+		Use:   "plan",
+		Short: "show what the next release would do without changing anything",
+		Args:  cobra.NoArgs,
+		Run:   planHandler,
+	})
+	cmd.AddCommand(newLintCommand())
+
 	err := config.InitConfig(cmd)
 	if err != nil {
 		fmt.Printf("\nConfig error: %s\n", err.Error())
@@ -73,86 +80,29 @@ func cliHandler(cmd *cobra.Command, args []string) {
 	logger := log.New(os.Stderr, "[go-semantic-release]: ", 0)
 	exitIfError := errorHandler(logger)
 
-	logger.Printf("version: %s\n", SRVERSION)
-
-	conf, err := config.NewConfig(cmd)
-	exitIfError(err)
-
-	pluginManager, err := plugin.NewManager(conf)
-	exitIfError(err)
-	exitHandler = func() {
-		pluginManager.Stop()
-	}
-
-	c := make(chan os.Signal)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		exitIfError(errors.New("terminating..."))
-	}()
-
-	ci, err := pluginManager.GetCICondition()
-	exitIfError(err)
-	logger.Printf("ci-condition plugin: %s@%s\n", ci.Name(), ci.Version())
-
-	prov, err := pluginManager.GetProvider()
-	exitIfError(err)
-	logger.Printf("provider plugin: %s@%s\n", prov.Name(), prov.Version())
-
-	if conf.ProviderOpts["token"] == "" {
-		conf.ProviderOpts["token"] = conf.Token
-	}
-	err = prov.Init(conf.ProviderOpts)
-	exitIfError(err)
-
-	logger.Println("getting default branch...")
-	repoInfo, err := prov.GetInfo()
-	exitIfError(err)
-	logger.Println("found default branch: " + repoInfo.DefaultBranch)
-	if repoInfo.Private {
-		logger.Println("repo is private")
-	}
-
-	currentBranch := ci.GetCurrentBranch()
-	if currentBranch == "" {
-		exitIfError(fmt.Errorf("current branch not found"))
-	}
-	logger.Println("found current branch: " + currentBranch)
-
-	if conf.MaintainedVersion != "" && currentBranch == repoInfo.DefaultBranch {
-		exitIfError(fmt.Errorf("maintained version not allowed on default branch"))
-	}
-
-	if conf.MaintainedVersion != "" {
-		logger.Println("found maintained version: " + conf.MaintainedVersion)
-		repoInfo.DefaultBranch = "*"
-	}
-
-	currentSha := ci.GetCurrentSHA()
-	logger.Println("found current sha: " + currentSha)
-
-	hooksExecutor, err := pluginManager.GetChainedHooksExecutor()
-	exitIfError(err)
-
-	hooksNames := hooksExecutor.GetNameVersionPairs()
-	if len(hooksNames) > 0 {
-		logger.Printf("hooks plugins: %s\n", strings.Join(hooksNames, ", "))
-	}
+	r := newReleaser(cmd, logger, exitIfError)
+	conf, result, hooksExecutor := r.conf, r.result, r.hooksExecutor
 
 	exitIfError(hooksExecutor.Init(conf.HooksOpts))
 
-	if !conf.NoCI {
+	if conf.Snapshot {
+		// snapshots are not published, so they are built on every branch
+		logger.Println("snapshot mode: skipping CI condition")
+		result.Snapshot = true
+	} else if !conf.NoCI {
 		logger.Println("running CI condition...")
 		conditionConfig := map[string]string{
-			"token":         conf.Token,
-			"defaultBranch": repoInfo.DefaultBranch,
-			"private":       fmt.Sprintf("%t", repoInfo.Private),
+			"token":           conf.Token,
+			"defaultBranch":   r.repoInfo.DefaultBranch,
+			"releaseBranches": r.releaseBranchNames(),
+			"private":         fmt.Sprintf("%t", r.repoInfo.Private),
 		}
 		for k, v := range conf.CIConditionOpts {
 			conditionConfig[k] = v
 		}
-		err = ci.RunCondition(conditionConfig)
+		err := r.ci.RunCondition(conditionConfig)
 		if err != nil {
+			result.setNoRelease(plugin.NoReleaseReasonCondition, err.Error())
 			herr := hooksExecutor.NoRelease(plugin.NoReleaseReasonCondition, err.Error())
 			if herr != nil {
 				logger.Printf("there was an error executing the hooks plugins: %s", herr.Error())
@@ -162,44 +112,32 @@ func cliHandler(cmd *cobra.Command, args []string) {
 
 	}
 
-	logger.Println("getting latest release...")
-	matchRegex := ""
-	match := strings.TrimSpace(conf.Match)
-	if match != "" {
-		logger.Printf("getting latest release matching %s...", match)
-		matchRegex = "^" + match
+	statuses := make(map[releaseStatus]int)
+	packages := r.packages()
+	plans := make([]*packagePlan, len(packages))
+	for i, pkg := range packages {
+		if r.monorepo {
+			logger.Printf("planning package %s...", pkg.Name)
+		}
+		plans[i] = r.planPackage(pkg, nestedPackagePaths(pkg, packages))
 	}
-	releases, err := prov.GetReleases(matchRegex)
-	exitIfError(err)
-	release, err := semrel.GetLatestReleaseFromReleases(releases, conf.MaintainedVersion)
-	exitIfError(err)
-	logger.Println("found version: " + release.Version)
-
-	if strings.Contains(conf.MaintainedVersion, "-") && semver.MustParse(release.Version).Prerelease() == "" {
-		exitIfError(fmt.Errorf("no pre-release for this version possible"))
+	for i, plan := range plans {
+		if plan.status == releaseStatusReleased && !conf.Dry {
+			if conf.Snapshot {
+				logger.Println("writing snapshot version " + plan.newRelease.NewVersion)
+				exitIfError(r.writeSnapshot(packages[i], plan))
+			}
+			statuses[releaseStatusReleased]++
+			continue
+		}
+		statuses[r.skipRelease(packages[i], plan)]++
 	}
 
-	if release.SHA == currentSha {
-		logger.Println("no new commits,write last release version")
-		exitIfError(ioutil.WriteFile(".version", []byte(release.Version), 0644))
-		return
+	if statuses[releaseStatusDryRun] > 0 {
+		exitIfError(errors.New("DRY RUN: no release was created"), 0)
 	}
-
-	logger.Println("getting commits...")
-	rawCommits, err := prov.GetCommits(release.SHA, currentSha)
-	exitIfError(err)
-
-	logger.Println("analyzing commits...")
-	commitAnalyzer, err := pluginManager.GetCommitAnalyzer()
-	exitIfError(err)
-	logger.Printf("commit-analyzer plugin: %s@%s\n", commitAnalyzer.Name(), commitAnalyzer.Version())
-	exitIfError(commitAnalyzer.Init(conf.ChangelogGeneratorOpts))
-
-	commits := commitAnalyzer.Analyze(rawCommits)
-
-	logger.Println("calculating new version...")
-	newVer := semrel.GetNewVersion(conf, commits, release)
-	if newVer == "" {
+	if statuses[releaseStatusReleased] == 0 && statuses[releaseStatusNoChange] > 0 {
+		result.setNoRelease(plugin.NoReleaseReasonNoChange, "")
 		herr := hooksExecutor.NoRelease(plugin.NoReleaseReasonNoChange, "")
 		if herr != nil {
 			logger.Printf("there was an error executing the hooks plugins: %s", herr.Error())
@@ -211,72 +149,22 @@ func cliHandler(cmd *cobra.Command, args []string) {
 			exitIfError(errNoChange, 65)
 		}
 	}
-	logger.Println("new version: " + newVer)
-
-	logger.Println("generating changelog...")
-	changelogGenerator, err := pluginManager.GetChangelogGenerator()
-	exitIfError(err)
-	logger.Printf("changelog-generator plugin: %s@%s\n", changelogGenerator.Name(), changelogGenerator.Version())
-	exitIfError(changelogGenerator.Init(conf.ChangelogGeneratorOpts))
 
-	changelogRes := changelogGenerator.Generate(commits, release, newVer)
-	if conf.Changelog != "" {
-		oldFile := make([]byte, 0)
-		if conf.PrependChangelog {
-			oldFileData, err := ioutil.ReadFile(conf.Changelog)
-			if err == nil {
-				oldFile = append([]byte("\n"), oldFileData...)
-			}
-		}
-		changelogData := append([]byte(changelogRes), oldFile...)
-		exitIfError(ioutil.WriteFile(conf.Changelog, changelogData, 0644))
+	if conf.Snapshot {
+		r.writeResult(nil)
+		logger.Println("done, no release was created for the snapshot.")
+		return
 	}
 
-	if conf.Dry {
-		if conf.VersionFile {
-			exitIfError(ioutil.WriteFile(".version-unreleased", []byte(newVer), 0644))
+	if err := r.publish(packages, plans); err != nil {
+		result.setNoRelease(plugin.NoReleaseReasonFailure, err.Error())
+		herr := hooksExecutor.NoRelease(plugin.NoReleaseReasonFailure, err.Error())
+		if herr != nil {
+			logger.Printf("there was an error executing the hooks plugins: %s", herr.Error())
 		}
-		exitIfError(errors.New("DRY RUN: no release was created"), 0)
-	}
-
-	logger.Println("creating release...")
-	newRelease := &plugin.CreateReleaseConfig{
-		Changelog:  changelogRes,
-		NewVersion: newVer,
-		Prerelease: conf.Prerelease,
-		Branch:     currentBranch,
-		SHA:        currentSha,
-	}
-	exitIfError(prov.CreateRelease(newRelease))
-
-	if conf.Ghr {
-		exitIfError(ioutil.WriteFile(".ghr", []byte(fmt.Sprintf("-u %s -r %s v%s", repoInfo.Owner, repoInfo.Repo, newVer)), 0644))
-	}
-
-	if conf.VersionFile {
-		exitIfError(ioutil.WriteFile(".version", []byte(newVer), 0644))
-	}
-
-	if len(conf.UpdateFiles) > 0 {
-		logger.Println("updating files...")
-		updater, err := pluginManager.GetChainedUpdater()
 		exitIfError(err)
-		logger.Printf("files-updater plugins: %s\n", strings.Join(updater.GetNameVersionPairs(), ", "))
-		exitIfError(updater.Init(conf.FilesUpdaterOpts))
-
-		for _, f := range conf.UpdateFiles {
-			exitIfError(updater.Apply(f, newVer))
-		}
-	}
-
-	herr := hooksExecutor.Success(commits, release, &semrel.Release{
-		SHA:     currentSha,
-		Version: newVer,
-	}, changelogRes, repoInfo)
-
-	if herr != nil {
-		logger.Printf("there was an error executing the hooks plugins: %s", herr.Error())
 	}
 
+	r.writeResult(nil)
 	logger.Println("done.")
 }
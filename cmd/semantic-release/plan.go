@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/spf13/cobra"
+)
+
+func planHandler(cmd *cobra.Command, args []string) {
+	logger := log.New(os.Stderr, "[go-semantic-release]: ", 0)
+	exitIfError := errorHandler(logger)
+
+	r := newReleaser(cmd, logger, exitIfError)
+	// nothing is released by a plan
+	r.result.DryRun = true
+
+	packages := r.packages()
+	plans := make([]*packagePlan, len(packages))
+	for i, pkg := range packages {
+		if r.monorepo {
+			logger.Printf("planning package %s...", pkg.Name)
+		}
+		plans[i] = r.planPackage(pkg, nestedPackagePaths(pkg, packages))
+	}
+
+	if r.conf.Output == "json" {
+		r.writeResult(nil)
+		return
+	}
+	exitIfError(r.printPlan(os.Stdout, packages, plans))
+}
+
+// printPlan describes the changes a release would make.
+func (r *releaser) printPlan(w io.Writer, packages []config.Package, plans []*packagePlan) error {
+	sb := &strings.Builder{}
+	indent := ""
+	released := false
+	for i, plan := range plans {
+		pkg := packages[i]
+		if r.monorepo {
+			tags := pkg.TagPrefix
+			if pkg.TagFormat != "" {
+				tags = pkg.TagFormat
+			}
+			fmt.Fprintf(sb, "package %s (%s):\n", pkg.Name, tags)
+			indent = "  "
+		}
+		fmt.Fprintf(sb, "%slatest release: %s\n", indent, plan.release.Version)
+		switch plan.status {
+		case releaseStatusUpToDate:
+			fmt.Fprintf(sb, "%snext release: none (no new commits)\n", indent)
+			continue
+		case releaseStatusNoChange:
+			fmt.Fprintf(sb, "%snext release: none (no relevant changes in %s)\n", indent, describeCommits(plan.commits))
+			continue
+		}
+		released = true
+		fmt.Fprintf(sb, "%snext release: %s (%s, %s)\n", indent, plan.newRelease.NewVersion, plan.result.Bump, describeCommits(plan.commits))
+		if plan.result.ReleaseAs != nil {
+			fmt.Fprintf(sb, "%srelease-as: %s (commit %s)\n", indent, plan.result.ReleaseAs.Version, shortSHA(plan.result.ReleaseAs.SHA))
+		}
+		if r.conf.Snapshot {
+			fmt.Fprintf(sb, "%stag: none (snapshot of %s)\n", indent, plan.newRelease.SHA)
+		} else {
+			fmt.Fprintf(sb, "%stag: %s at %s\n", indent, plan.newRelease.TagName(), plan.newRelease.SHA)
+		}
+		if r.branch != nil && r.branch.Channel != "" {
+			fmt.Fprintf(sb, "%schannel: %s\n", indent, r.branch.Channel)
+		}
+		if len(plan.newRelease.Assets) > 0 {
+			fmt.Fprintf(sb, "%sassets: %s\n", indent, strings.Join(plan.newRelease.Assets, ", "))
+		}
+		if files := r.writtenFiles(pkg); len(files) > 0 {
+			fmt.Fprintf(sb, "%sfiles to write: %s\n", indent, strings.Join(files, ", "))
+		}
+		if len(plan.updateFiles) > 0 {
+			fmt.Fprintf(sb, "%sfiles to update (%s): %s\n", indent, strings.Join(r.conf.FilesUpdaterPlugins, ", "), strings.Join(plan.updateFiles, ", "))
+		}
+		fmt.Fprintf(sb, "%schangelog:\n", indent)
+		for _, line := range strings.Split(strings.TrimRight(plan.newRelease.Changelog, "\n"), "\n") {
+			if line == "" {
+				sb.WriteString("\n")
+				continue
+			}
+			fmt.Fprintf(sb, "%s  %s\n", indent, line)
+		}
+	}
+
+	hooks := "none"
+	if names := r.hooksExecutor.GetNameVersionPairs(); len(names) > 0 && !r.conf.Snapshot {
+		event := "no release"
+		if released {
+			event = "success"
+		}
+		hooks = fmt.Sprintf("%s (%s)", event, strings.Join(names, ", "))
+	}
+	fmt.Fprintf(sb, "hooks: %s\n", hooks)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// describeCommits returns the number of commits and of the skipped ones.
+func describeCommits(commits []*semrel.Commit) string {
+	if skipped := skippedCommits(commits); skipped > 0 {
+		return fmt.Sprintf("%d commits, %d skipped", len(commits), skipped)
+	}
+	return fmt.Sprintf("%d commits", len(commits))
+}
+
+// writtenFiles returns the files that a release of pkg writes itself.
+func (r *releaser) writtenFiles(pkg config.Package) []string {
+	files := make([]string, 0)
+	if r.conf.Snapshot {
+		if r.conf.VersionFile {
+			files = append(files, filepath.Join(pkg.Path, ".version"))
+		}
+		return files
+	}
+	if pkg.Changelog != "" {
+		files = append(files, pkg.Changelog)
+	}
+	if r.conf.Ghr {
+		files = append(files, filepath.Join(pkg.Path, ".ghr"))
+	}
+	if r.conf.VersionFile {
+		files = append(files, filepath.Join(pkg.Path, ".version"))
+	}
+	return files
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintPlan(t *testing.T) {
+	r := &releaser{
+		conf:          &config.Config{FilesUpdaterPlugins: []string{"npm"}, VersionFile: true},
+		hooksExecutor: &plugin.ChainedHooksExecutor{},
+		monorepo:      true,
+	}
+	packages := []config.Package{
+		{Name: "api", Path: "api", TagPrefix: "api/v", Changelog: "api/CHANGELOG.md"},
+		{Name: "web", Path: "web", TagPrefix: "web/v"},
+	}
+	plans := []*packagePlan{
+		{
+			release:     &semrel.Release{Version: "1.0.0"},
+			commits:     []*semrel.Commit{{SHA: "abcd"}, {SHA: "bcde", Raw: []string{"feat: hidden [skip release]"}}},
+			newRelease:  &plugin.CreateReleaseConfig{NewVersion: "2.0.0", Tag: "api/v2.0.0", SHA: "abcd", Changelog: "## 2.0.0\n\n* feature\n"},
+			updateFiles: []string{"api/package.json"},
+			status:      releaseStatusReleased,
+			result:      &packageResult{Bump: "major", ReleaseAs: &releaseInfo{SHA: "abcd", Version: "2.0.0"}},
+		},
+		{
+			release: &semrel.Release{Version: "2.0.0"},
+			status:  releaseStatusUpToDate,
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.printPlan(buf, packages, plans))
+	require.Equal(t, `package api (api/v):
+  latest release: 1.0.0
+  next release: 2.0.0 (major, 2 commits, 1 skipped)
+  release-as: 2.0.0 (commit abcd)
+  tag: api/v2.0.0 at abcd
+  files to write: api/CHANGELOG.md, api/.version
+  files to update (npm): api/package.json
+  changelog:
+    ## 2.0.0
+
+    * feature
+package web (web/v):
+  latest release: 2.0.0
+  next release: none (no new commits)
+hooks: none
+`, buf.String())
+}
@@ -0,0 +1,608 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/spf13/cobra"
+)
+
+type releaseStatus int
+
+const (
+	releaseStatusReleased releaseStatus = iota
+	releaseStatusDryRun
+	releaseStatusNoChange
+	releaseStatusUpToDate
+)
+
+// releaser holds everything that is shared by the releases of all packages.
+type releaser struct {
+	conf               *config.Config
+	logger             *log.Logger
+	exitIfError        func(error, ...int)
+	pluginManager      *plugin.Manager
+	ci                 plugin.CICondition
+	prov               plugin.Provider
+	commitAnalyzer     plugin.CommitAnalyzer
+	changelogGenerator plugin.ChangelogGenerator
+	hooksExecutor      *plugin.ChainedHooksExecutor
+	updater            *plugin.ChainedUpdater
+	repoInfo           *plugin.RepositoryInfo
+	currentBranch      string
+	currentSha         string
+	branches           []config.Branch
+	branch             *config.Branch
+	monorepo           bool
+	result             *runResult
+	// commits holds the commits since a previous release by its SHA
+	commits map[string][]*semrel.RawCommit
+}
+
+// packagePlan is the outcome of the read-only part of a package release.
+type packagePlan struct {
+	release     *semrel.Release
+	commits     []*semrel.Commit
+	newRelease  *plugin.CreateReleaseConfig
+	updateFiles []string
+	status      releaseStatus
+	result      *packageResult
+}
+
+// newReleaser loads the config and the plugins and resolves the repository
+// state. It does not change anything, neither locally nor at the provider.
+func newReleaser(cmd *cobra.Command, logger *log.Logger, exitIfError func(error, ...int)) *releaser {
+	logger.Printf("version: %s\n", SRVERSION)
+
+	r := loadReleaser(cmd, logger, exitIfError)
+	exitHandler = func(err error) {
+		r.pluginManager.Stop()
+		r.writeResult(err)
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		exitIfError(errors.New("terminating..."))
+	}()
+
+	r.initProvider()
+	r.initBranch()
+
+	r.currentSha = r.ci.GetCurrentSHA()
+	logger.Println("found current sha: " + r.currentSha)
+
+	var err error
+	r.hooksExecutor, err = r.pluginManager.GetChainedHooksExecutor()
+	exitIfError(err)
+
+	hooksNames := r.hooksExecutor.GetNameVersionPairs()
+	if len(hooksNames) > 0 {
+		logger.Printf("hooks plugins: %s\n", strings.Join(hooksNames, ", "))
+	}
+
+	r.initCommitAnalyzer()
+
+	r.changelogGenerator, err = r.pluginManager.GetChangelogGenerator()
+	exitIfError(err)
+	logger.Printf("changelog-generator plugin: %s@%s\n", r.changelogGenerator.Name(), r.changelogGenerator.Version())
+	exitIfError(r.changelogGenerator.Init(r.conf.ChangelogGeneratorOpts))
+
+	return r
+}
+
+// loadReleaser reads the config and creates the plugin manager, the plugins
+// are initialized by the init methods.
+func loadReleaser(cmd *cobra.Command, logger *log.Logger, exitIfError func(error, ...int)) *releaser {
+	conf, err := config.NewConfig(cmd)
+	exitIfError(err)
+
+	pluginManager, err := plugin.NewManager(conf)
+	exitIfError(err)
+	return &releaser{
+		conf:          conf,
+		logger:        logger,
+		exitIfError:   exitIfError,
+		pluginManager: pluginManager,
+		result:        &runResult{DryRun: conf.Dry},
+	}
+}
+
+// initProvider initializes the ci-condition and the provider plugin.
+func (r *releaser) initProvider() {
+	conf, logger, exitIfError := r.conf, r.logger, r.exitIfError
+	var err error
+	r.ci, err = r.pluginManager.GetCICondition()
+	exitIfError(err)
+	logger.Printf("ci-condition plugin: %s@%s\n", r.ci.Name(), r.ci.Version())
+
+	r.prov, err = r.pluginManager.GetProvider()
+	exitIfError(err)
+	logger.Printf("provider plugin: %s@%s\n", r.prov.Name(), r.prov.Version())
+
+	if conf.ProviderOpts["token"] == "" {
+		conf.ProviderOpts["token"] = conf.Token
+	}
+	r.monorepo = len(conf.Packages) > 0
+	if r.monorepo {
+		// the files of each commit are needed to assign it to the packages
+		conf.ProviderOpts["commit_files"] = "true"
+	}
+	// the provider parses the tags with the configured version scheme
+	conf.ProviderOpts["version_scheme"] = conf.VersionScheme
+	conf.ProviderOpts["calver_format"] = conf.CalVerFormat
+	exitIfError(r.prov.Init(conf.ProviderOpts))
+}
+
+// initBranch finds the default and the current branch and resolves the
+// release branch.
+func (r *releaser) initBranch() {
+	logger, exitIfError := r.logger, r.exitIfError
+	var err error
+	logger.Println("getting default branch...")
+	r.repoInfo, err = r.prov.GetInfo()
+	exitIfError(err)
+	logger.Println("found default branch: " + r.repoInfo.DefaultBranch)
+	if r.repoInfo.Private {
+		logger.Println("repo is private")
+	}
+
+	r.currentBranch = r.ci.GetCurrentBranch()
+	if r.currentBranch == "" {
+		exitIfError(fmt.Errorf("current branch not found"))
+	}
+	logger.Println("found current branch: " + r.currentBranch)
+
+	exitIfError(r.resolveBranch())
+}
+
+// initCommitAnalyzer initializes the commit analyzer plugin.
+func (r *releaser) initCommitAnalyzer() {
+	var err error
+	r.commitAnalyzer, err = r.pluginManager.GetCommitAnalyzer()
+	r.exitIfError(err)
+	r.logger.Printf("commit-analyzer plugin: %s@%s\n", r.commitAnalyzer.Name(), r.commitAnalyzer.Version())
+	r.exitIfError(r.commitAnalyzer.Init(r.conf.CommitAnalyzerOpts))
+}
+
+// resolveBranch determines the release branches and the config of the current
+// branch. Without configured branches only the default branch is released,
+// or the current branch if a maintained version is set.
+func (r *releaser) resolveBranch() error {
+	conf, logger := r.conf, r.logger
+	r.branches = conf.Branches
+	if len(r.branches) == 0 {
+		r.branches = []config.Branch{{Name: r.repoInfo.DefaultBranch}}
+	}
+	if conf.MaintainedVersion != "" {
+		if len(conf.Branches) > 0 {
+			return errors.New("maintained version can not be combined with branches")
+		}
+		if r.currentBranch == r.repoInfo.DefaultBranch {
+			return errors.New("maintained version not allowed on default branch")
+		}
+		logger.Println("found maintained version: " + conf.MaintainedVersion)
+		r.branches = []config.Branch{{Name: r.currentBranch, Range: conf.MaintainedVersion}}
+	}
+
+	for i := range r.branches {
+		if r.branches[i].Match(r.currentBranch) {
+			r.branch = &r.branches[i]
+			break
+		}
+	}
+	if r.branch == nil {
+		// the CI condition rejects the branch, unless it is skipped
+		logger.Printf("%s is not a release branch", r.currentBranch)
+		r.branch = &config.Branch{Name: r.currentBranch}
+		return nil
+	}
+	if r.branch.Channel != "" {
+		logger.Println("found release channel: " + r.branch.Channel)
+	}
+	r.result.Channel = r.branch.Channel
+	return nil
+}
+
+// releaseBranchNames returns the names of all release branches.
+func (r *releaser) releaseBranchNames() string {
+	names := make([]string, len(r.branches))
+	for i, b := range r.branches {
+		names[i] = b.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// writeResult prints the result document if it was requested.
+func (r *releaser) writeResult(err error) {
+	if r.conf.Output != "json" {
+		return
+	}
+	r.result.finish(err)
+	if werr := r.result.write(os.Stdout); werr != nil {
+		r.logger.Printf("could not write the result: %s", werr.Error())
+	}
+}
+
+// packages returns the configured packages or the whole repository as single
+// package if the monorepo mode is not used.
+func (r *releaser) packages() []config.Package {
+	if r.monorepo {
+		return r.conf.Packages
+	}
+	return []config.Package{{TagFormat: r.conf.TagFormat, Changelog: r.conf.Changelog, UpdateFiles: r.conf.UpdateFiles, Assets: r.conf.Assets}}
+}
+
+// nestedPackagePaths returns the paths of the other packages that are located
+// inside of pkg, their commits do not belong to pkg.
+func nestedPackagePaths(pkg config.Package, packages []config.Package) []string {
+	nested := make([]string, 0)
+	for _, other := range packages {
+		if other.Path == pkg.Path {
+			continue
+		}
+		if pkg.Path == "" || strings.HasPrefix(other.Path, pkg.Path+"/") {
+			nested = append(nested, other.Path)
+		}
+	}
+	return nested
+}
+
+// releaseRegex returns the expression of the tags that belong to pkg, the
+// version part of the tags has to start with the configured match.
+func (r *releaser) releaseRegex(pkg config.Package, tagFormat *semrel.TagFormat) string {
+	match := strings.TrimSpace(r.conf.Match)
+	if !r.monorepo && pkg.TagTemplate() == semrel.DefaultTagFormat {
+		// the match used to include the "v" of the default tags
+		match = strings.TrimPrefix(match, "v")
+	}
+	if match != "" {
+		r.logger.Printf("getting latest release matching %s...", match)
+	}
+	return tagFormat.Regex(match)
+}
+
+// getCommits returns the commits from the release at fromSha to the current
+// commit. They are fetched once for every previous release, as the packages of
+// a monorepo often share it.
+func (r *releaser) getCommits(fromSha string) ([]*semrel.RawCommit, error) {
+	if commits, ok := r.commits[fromSha]; ok {
+		return commits, nil
+	}
+	commits, err := r.prov.GetCommits(fromSha, r.currentSha)
+	if err != nil {
+		return nil, err
+	}
+	if r.commits == nil {
+		r.commits = make(map[string][]*semrel.RawCommit)
+	}
+	r.commits[fromSha] = commits
+	return commits, nil
+}
+
+// planPackage calculates the next release of a package without any side effects.
+func (r *releaser) planPackage(pkg config.Package, nestedPaths []string) *packagePlan {
+	conf, logger, exitIfError := r.conf, r.logger, r.exitIfError
+	result := r.result.addPackage(pkg.Name, r.monorepo)
+
+	tagFormat, err := semrel.NewTagFormat(pkg.TagTemplate())
+	exitIfError(err)
+
+	logger.Println("getting latest release...")
+	releases, err := r.prov.GetReleases(r.releaseRegex(pkg, tagFormat))
+	exitIfError(err)
+	release, err := semrel.GetLatestBranchRelease(r.conf, releases, r.branch)
+	exitIfError(err)
+	logger.Println("found version: " + release.Version)
+	result.PreviousRelease = &releaseInfo{SHA: release.SHA, Version: release.Version}
+	plan := &packagePlan{release: release, result: result}
+
+	if strings.Contains(r.branch.Range, "-") && semver.MustParse(release.Version).Prerelease() == "" {
+		exitIfError(fmt.Errorf("no pre-release for this version possible"))
+	}
+
+	if release.SHA == r.currentSha {
+		logger.Println("no new commits")
+		plan.status = releaseStatusUpToDate
+		return plan
+	}
+
+	logger.Println("getting commits...")
+	rawCommits, err := r.getCommits(release.SHA)
+	exitIfError(err)
+	if r.monorepo {
+		rawCommits = semrel.FilterCommitsByPath(rawCommits, pkg.Path, nestedPaths)
+		logger.Printf("found %d commits touching %s", len(rawCommits), pkg.Name)
+	}
+
+	logger.Println("analyzing commits...")
+	plan.commits = r.commitAnalyzer.Analyze(rawCommits)
+	result.setCommits(plan.commits)
+
+	if skipped := skippedCommits(plan.commits); skipped > 0 {
+		logger.Printf("excluding %d skipped commits from the version calculation", skipped)
+	}
+
+	logger.Println("calculating new version...")
+	var newVer string
+	if conf.Snapshot {
+		newVer, err = semrel.GetSnapshotVersion(conf, r.branch, plan.commits, release, releases, r.currentSha, time.Now())
+	} else {
+		newVer, err = semrel.GetNewVersion(conf, r.branch, plan.commits, release, releases)
+	}
+	exitIfError(err)
+	if newVer == "" {
+		if r.monorepo {
+			logger.Printf("no change for package %s", pkg.Name)
+		}
+		plan.status = releaseStatusNoChange
+		return plan
+	}
+	logger.Println("new version: " + newVer)
+	result.NewVersion = newVer
+	result.Bump = versionBump(release.Version, newVer)
+	if c := semrel.ReleaseAsCommit(plan.commits, release); c != nil {
+		logger.Printf("version set by Release-As in commit %s", shortSHA(c.SHA))
+		result.ReleaseAs = &releaseInfo{SHA: c.SHA, Version: newVer}
+	}
+
+	logger.Println("generating changelog...")
+	changelogRes := r.changelogGenerator.Generate(plan.commits, release, newVer)
+	result.Changelog = changelogRes
+
+	plan.newRelease = &plugin.CreateReleaseConfig{
+		Changelog:  changelogRes,
+		NewVersion: newVer,
+		Prerelease: conf.Prerelease || r.branch.Prerelease != "",
+		Branch:     r.currentBranch,
+		SHA:        r.currentSha,
+		Tag:        tagFormat.Tag(newVer),
+	}
+	if !conf.Snapshot {
+		result.Tag = plan.newRelease.TagName()
+	}
+
+	for _, f := range pkg.UpdateFiles {
+		plan.updateFiles = append(plan.updateFiles, filepath.Join(pkg.Path, f))
+	}
+	for _, a := range pkg.Assets {
+		plan.newRelease.Assets = append(plan.newRelease.Assets, filepath.Join(pkg.Path, a))
+	}
+	plan.status = releaseStatusReleased
+	return plan
+}
+
+// skippedCommits returns the number of commits that are excluded from the
+// version calculation.
+func skippedCommits(commits []*semrel.Commit) int {
+	skipped := 0
+	for _, c := range commits {
+		if semrel.IsSkipped(c) {
+			skipped++
+		}
+	}
+	return skipped
+}
+
+// skipRelease handles a package that is not published. The version file of
+// an up-to-date package is refreshed and a dry run writes the changelog and
+// the unreleased version.
+func (r *releaser) skipRelease(pkg config.Package, plan *packagePlan) releaseStatus {
+	conf, logger, exitIfError := r.conf, r.logger, r.exitIfError
+	switch plan.status {
+	case releaseStatusUpToDate:
+		logger.Println("write last release version")
+		exitIfError(ioutil.WriteFile(filepath.Join(pkg.Path, ".version"), []byte(plan.release.Version), 0644))
+		return plan.status
+	case releaseStatusNoChange:
+		return plan.status
+	}
+
+	if pkg.Changelog != "" {
+		exitIfError(ioutil.WriteFile(pkg.Changelog, r.changelogData(pkg, plan.newRelease.Changelog), 0644))
+	}
+	if conf.VersionFile {
+		exitIfError(ioutil.WriteFile(filepath.Join(pkg.Path, ".version-unreleased"), []byte(plan.newRelease.NewVersion), 0644))
+	}
+	return releaseStatusDryRun
+}
+
+func (r *releaser) changelogData(pkg config.Package, changelog string) []byte {
+	oldFile := make([]byte, 0)
+	if r.conf.PrependChangelog {
+		oldFileData, err := ioutil.ReadFile(pkg.Changelog)
+		if err == nil {
+			oldFile = append([]byte("\n"), oldFileData...)
+		}
+	}
+	return append([]byte(changelog), oldFile...)
+}
+
+// publish releases the planned packages. The local files are prepared first
+// and then the releases are created at the provider. If one of these steps
+// fails, the created releases are deleted and the files are restored. The
+// success hooks are only executed after all releases were created, their
+// errors are logged because the releases are not rolled back anymore.
+func (r *releaser) publish(packages []config.Package, plans []*packagePlan) error {
+	tx := newFileTransaction()
+	published := make([]*packagePlan, 0)
+	err := r.prepare(tx, packages, plans)
+	if err == nil {
+		published, err = r.createReleases(plans)
+	}
+	if err != nil {
+		r.rollback(tx, published)
+		return err
+	}
+	r.runSuccessHooks(plans)
+	return nil
+}
+
+// prepare verifies that the releases can be created and writes the files
+// that belong to them.
+func (r *releaser) prepare(tx *fileTransaction, packages []config.Package, plans []*packagePlan) error {
+	conf, logger := r.conf, r.logger
+	for _, plan := range plans {
+		if plan.status != releaseStatusReleased {
+			continue
+		}
+		// a missing asset must not be noticed after the tag was pushed
+		if _, err := plugin.ExpandAssets(plan.newRelease.Assets); err != nil {
+			return err
+		}
+		if len(plan.updateFiles) > 0 {
+			if _, err := r.filesUpdater(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, plan := range plans {
+		if plan.status != releaseStatusReleased {
+			continue
+		}
+		pkg, newRelease := packages[i], plan.newRelease
+		if pkg.Changelog != "" {
+			if err := tx.writeFile(pkg.Changelog, r.changelogData(pkg, newRelease.Changelog)); err != nil {
+				return err
+			}
+		}
+
+		if conf.Ghr {
+			ghr := fmt.Sprintf("-u %s -r %s %s", r.repoInfo.Owner, r.repoInfo.Repo, newRelease.TagName())
+			if err := tx.writeFile(filepath.Join(pkg.Path, ".ghr"), []byte(ghr)); err != nil {
+				return err
+			}
+		}
+
+		if conf.VersionFile {
+			if err := tx.writeFile(filepath.Join(pkg.Path, ".version"), []byte(newRelease.NewVersion)); err != nil {
+				return err
+			}
+		}
+
+		if len(plan.updateFiles) > 0 {
+			logger.Println("updating files...")
+		}
+		for _, f := range plan.updateFiles {
+			if err := tx.track(f); err != nil {
+				return err
+			}
+			if err := r.updater.Apply(f, newRelease.NewVersion); err != nil {
+				return err
+			}
+			plan.result.UpdatedFiles = append(plan.result.UpdatedFiles, f)
+		}
+	}
+	return nil
+}
+
+// filesUpdater loads and initializes the files-updater plugins on first use.
+func (r *releaser) filesUpdater() (*plugin.ChainedUpdater, error) {
+	if r.updater != nil {
+		return r.updater, nil
+	}
+	updater, err := r.pluginManager.GetChainedUpdater()
+	if err != nil {
+		return nil, err
+	}
+	r.logger.Printf("files-updater plugins: %s\n", strings.Join(updater.GetNameVersionPairs(), ", "))
+	if err := updater.Init(r.conf.FilesUpdaterOpts); err != nil {
+		return nil, err
+	}
+	r.updater = updater
+	return updater, nil
+}
+
+// writeSnapshot writes the snapshot version of a package to the version file
+// and the files of the files-updater plugins. Nothing is published.
+func (r *releaser) writeSnapshot(pkg config.Package, plan *packagePlan) error {
+	newVer := plan.newRelease.NewVersion
+	if r.conf.VersionFile {
+		if err := ioutil.WriteFile(filepath.Join(pkg.Path, ".version"), []byte(newVer), 0644); err != nil {
+			return err
+		}
+	}
+	if len(plan.updateFiles) == 0 {
+		return nil
+	}
+	updater, err := r.filesUpdater()
+	if err != nil {
+		return err
+	}
+	r.logger.Println("updating files...")
+	for _, f := range plan.updateFiles {
+		if err := updater.Apply(f, newVer); err != nil {
+			return err
+		}
+		plan.result.UpdatedFiles = append(plan.result.UpdatedFiles, f)
+	}
+	return nil
+}
+
+// createReleases creates the releases at the provider and returns the plans
+// that were published, also if an error occurred.
+func (r *releaser) createReleases(plans []*packagePlan) ([]*packagePlan, error) {
+	published := make([]*packagePlan, 0)
+	for _, plan := range plans {
+		if plan.status != releaseStatusReleased {
+			continue
+		}
+		r.logger.Printf("creating release %s...", plan.newRelease.TagName())
+		if err := r.prov.CreateRelease(plan.newRelease); err != nil {
+			// the provider may have created a part of the release
+			return append(published, plan), err
+		}
+		plan.result.Released = true
+		published = append(published, plan)
+	}
+	return published, nil
+}
+
+func (r *releaser) runSuccessHooks(plans []*packagePlan) {
+	for _, plan := range plans {
+		if plan.status != releaseStatusReleased {
+			continue
+		}
+		err := r.hooksExecutor.Success(plan.commits, plan.release, &semrel.Release{
+			SHA:     r.currentSha,
+			Version: plan.newRelease.NewVersion,
+		}, plan.newRelease.Changelog, r.repoInfo)
+		if err != nil {
+			r.logger.Printf("there was an error executing the hooks plugins: %s", err.Error())
+		}
+	}
+}
+
+// rollback deletes the published releases and restores the changed files.
+// Errors are only logged because the original error is reported.
+func (r *releaser) rollback(tx *fileTransaction, published []*packagePlan) {
+	logger := r.logger
+	for i := len(published) - 1; i >= 0; i-- {
+		plan := published[i]
+		logger.Printf("deleting release %s...", plan.newRelease.TagName())
+		if err := r.prov.DeleteRelease(plan.newRelease); err != nil {
+			logger.Printf("could not delete release %s: %s", plan.newRelease.TagName(), err.Error())
+			continue
+		}
+		plan.result.Released = false
+		plan.result.RolledBack = true
+	}
+	logger.Println("restoring files...")
+	if err := tx.rollback(); err != nil {
+		logger.Println(err.Error())
+	}
+}
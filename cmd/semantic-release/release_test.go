@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	plugin.Provider
+	created []string
+	deleted []string
+	fetched []string
+	failTag string
+}
+
+func (p *testProvider) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	p.fetched = append(p.fetched, fromSha)
+	return []*semrel.RawCommit{{SHA: toSha, RawMessage: "fix: from " + fromSha}}, nil
+}
+
+func (p *testProvider) CreateRelease(release *plugin.CreateReleaseConfig) error {
+	p.created = append(p.created, release.TagName())
+	if release.TagName() == p.failTag {
+		return errors.New("release failed")
+	}
+	return nil
+}
+
+func (p *testProvider) DeleteRelease(release *plugin.CreateReleaseConfig) error {
+	p.deleted = append(p.deleted, release.TagName())
+	return nil
+}
+
+type failingHooks struct {
+	plugin.Hooks
+	released []string
+}
+
+func (h *failingHooks) Success(commits []*semrel.Commit, prevRelease, newRelease *semrel.Release, changelog string, repoInfo *plugin.RepositoryInfo) error {
+	h.released = append(h.released, newRelease.Version)
+	return errors.New("hook failed")
+}
+
+func TestPublishRollsBack(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "publish")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	changelog := filepath.Join(dir, "CHANGELOG.md")
+	require.NoError(ioutil.WriteFile(changelog, []byte("# 1.0.0\n"), 0644))
+
+	prov := &testProvider{failTag: "web/v2.1.0"}
+	hooks := &failingHooks{}
+	r := &releaser{
+		conf:          &config.Config{VersionFile: true, PrependChangelog: true},
+		logger:        log.New(ioutil.Discard, "", 0),
+		prov:          prov,
+		hooksExecutor: &plugin.ChainedHooksExecutor{HooksChain: []plugin.Hooks{hooks}},
+		repoInfo:      &plugin.RepositoryInfo{},
+		result:        &runResult{},
+	}
+	api := r.result.addPackage("api", true)
+	web := r.result.addPackage("web", true)
+	require.NoError(os.Mkdir(filepath.Join(dir, "web"), 0755))
+	packages := []config.Package{{Path: dir, Changelog: changelog}, {Path: filepath.Join(dir, "web")}}
+	plans := []*packagePlan{{
+		release:    &semrel.Release{Version: "1.0.0"},
+		newRelease: &plugin.CreateReleaseConfig{NewVersion: "1.1.0", Changelog: "# 1.1.0\n", Tag: "v1.1.0"},
+		status:     releaseStatusReleased,
+		result:     api,
+	}, {
+		release:    &semrel.Release{Version: "2.0.0"},
+		newRelease: &plugin.CreateReleaseConfig{NewVersion: "2.1.0", Tag: "web/v2.1.0"},
+		status:     releaseStatusReleased,
+		result:     web,
+	}}
+
+	err = r.publish(packages, plans)
+	require.EqualError(err, "release failed")
+	require.Equal([]string{"v1.1.0", "web/v2.1.0"}, prov.created)
+	require.Equal([]string{"web/v2.1.0", "v1.1.0"}, prov.deleted)
+	require.False(api.Released)
+	require.True(api.RolledBack)
+	// the success hooks must not announce a release that was deleted
+	require.Empty(hooks.released)
+
+	data, err := ioutil.ReadFile(changelog)
+	require.NoError(err)
+	require.Equal("# 1.0.0\n", string(data))
+	for _, path := range []string{dir, filepath.Join(dir, "web")} {
+		_, err = os.Stat(filepath.Join(path, ".version"))
+		require.True(os.IsNotExist(err))
+	}
+}
+
+func TestPublishKeepsReleasesOnHookError(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "publish")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	prov := &testProvider{}
+	hooks := &failingHooks{}
+	r := &releaser{
+		conf:          &config.Config{VersionFile: true},
+		logger:        log.New(ioutil.Discard, "", 0),
+		prov:          prov,
+		hooksExecutor: &plugin.ChainedHooksExecutor{HooksChain: []plugin.Hooks{hooks}},
+		repoInfo:      &plugin.RepositoryInfo{},
+		result:        &runResult{},
+	}
+	api := r.result.addPackage("api", true)
+	web := r.result.addPackage("web", true)
+	packages := []config.Package{{Path: dir}, {Path: dir}}
+	plans := []*packagePlan{
+		{release: &semrel.Release{}, newRelease: &plugin.CreateReleaseConfig{NewVersion: "1.1.0"}, status: releaseStatusReleased, result: api},
+		{release: &semrel.Release{}, newRelease: &plugin.CreateReleaseConfig{NewVersion: "2.1.0"}, status: releaseStatusReleased, result: web},
+	}
+
+	require.NoError(r.publish(packages, plans))
+	require.Empty(prov.deleted)
+	require.True(api.Released)
+	require.True(web.Released)
+	require.Equal([]string{"1.1.0", "2.1.0"}, hooks.released)
+	data, err := ioutil.ReadFile(filepath.Join(dir, ".version"))
+	require.NoError(err)
+	require.Equal("2.1.0", string(data))
+}
+
+func TestGetCommitsOncePerRelease(t *testing.T) {
+	require := require.New(t)
+	prov := &testProvider{}
+	r := &releaser{prov: prov, currentSha: "head"}
+
+	for _, fromSha := range []string{"aaaa", "bbbb", "aaaa", "", "bbbb", ""} {
+		commits, err := r.getCommits(fromSha)
+		require.NoError(err)
+		require.Equal("fix: from "+fromSha, commits[0].RawMessage)
+	}
+	require.Equal([]string{"aaaa", "bbbb", ""}, prov.fetched)
+}
+
+func TestReleaseRegex(t *testing.T) {
+	require := require.New(t)
+	tagFormat, err := semrel.NewTagFormat(semrel.DefaultTagFormat)
+	require.NoError(err)
+	for _, match := range []string{"", "2", "v2"} {
+		r := &releaser{conf: &config.Config{Match: match}, logger: log.New(ioutil.Discard, "", 0)}
+		re := regexp.MustCompile(r.releaseRegex(config.Package{TagFormat: semrel.DefaultTagFormat}, tagFormat))
+		require.True(re.MatchString("v2.0.0"), match)
+		require.False(re.MatchString("2.0.0"), match)
+		require.False(re.MatchString("api/v2.0.0"), match)
+		require.Equal(match == "", re.MatchString("v1.0.0"), match)
+		scheme, err := semrel.NewVersionScheme("semver", "")
+		require.NoError(err)
+		version, err := semrel.ParseTagVersion(scheme, re, "v2.0.0")
+		require.NoError(err)
+		require.Equal("2.0.0", version)
+	}
+}
+
+func TestReleaseRegexCalVer(t *testing.T) {
+	require := require.New(t)
+	tagFormat, err := semrel.NewTagFormat(semrel.DefaultTagFormat)
+	require.NoError(err)
+	r := &releaser{conf: &config.Config{}, logger: log.New(ioutil.Discard, "", 0)}
+	re := regexp.MustCompile(r.releaseRegex(config.Package{TagFormat: semrel.DefaultTagFormat}, tagFormat))
+	scheme, err := semrel.NewVersionScheme("calver", "")
+	require.NoError(err)
+	version, err := semrel.ParseTagVersion(scheme, re, "v2026.10.0")
+	require.NoError(err)
+	require.Equal("2026.10.0", version)
+	require.Equal("v2026.10.1", tagFormat.Tag("2026.10.1"))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+// runResult is the machine-readable summary of a run that is printed with
+// --output json. In monorepo mode every package is listed in Packages,
+// otherwise the fields of the single package are inlined.
+type runResult struct {
+	*packageResult
+	Packages        []*packageResult `json:"packages,omitempty"`
+	DryRun          bool             `json:"dryRun"`
+	Snapshot        bool             `json:"snapshot,omitempty"`
+	Channel         string           `json:"channel,omitempty"`
+	NoReleaseReason string           `json:"noReleaseReason,omitempty"`
+	Message         string           `json:"message,omitempty"`
+	Error           string           `json:"error,omitempty"`
+}
+
+type packageResult struct {
+	Name            string          `json:"name,omitempty"`
+	Released        bool            `json:"released"`
+	PreviousRelease *releaseInfo    `json:"previousRelease,omitempty"`
+	NewVersion      string          `json:"newVersion,omitempty"`
+	Bump            string          `json:"bump"`
+	ReleaseAs       *releaseInfo    `json:"releaseAs,omitempty"`
+	Tag             string          `json:"tag,omitempty"`
+	Commits         []*commitResult `json:"commits"`
+	Changelog       string          `json:"changelog,omitempty"`
+	UpdatedFiles    []string        `json:"updatedFiles"`
+	RolledBack      bool            `json:"rolledBack,omitempty"`
+}
+
+type releaseInfo struct {
+	SHA     string `json:"sha"`
+	Version string `json:"version"`
+}
+
+type commitResult struct {
+	SHA         string     `json:"sha"`
+	Type        string     `json:"type"`
+	Scope       string     `json:"scope"`
+	Message     string     `json:"message"`
+	Bump        string     `json:"bump"`
+	Author      string     `json:"author,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`
+	PullRequest int        `json:"pullRequest,omitempty"`
+	Skipped     bool       `json:"skipped,omitempty"`
+}
+
+func (r *runResult) addPackage(name string, monorepo bool) *packageResult {
+	pkg := &packageResult{
+		Bump:         "none",
+		Commits:      make([]*commitResult, 0),
+		UpdatedFiles: make([]string, 0),
+	}
+	if monorepo {
+		pkg.Name = name
+		r.Packages = append(r.Packages, pkg)
+	} else {
+		r.packageResult = pkg
+	}
+	return pkg
+}
+
+func (r *runResult) setNoRelease(reason plugin.NoReleaseReason, message string) {
+	r.NoReleaseReason = reason.String()
+	r.Message = message
+}
+
+// finish records the error that terminated the run, unless the run ended
+// without a release on purpose.
+func (r *runResult) finish(err error) {
+	failed := r.NoReleaseReason == "" || r.NoReleaseReason == plugin.NoReleaseReasonFailure.String()
+	if err != nil && failed && !r.DryRun {
+		r.Error = err.Error()
+	}
+}
+
+func (r *runResult) write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (p *packageResult) setCommits(commits []*semrel.Commit) {
+	for _, c := range commits {
+		cr := &commitResult{
+			SHA:         c.SHA,
+			Type:        c.Type,
+			Scope:       c.Scope,
+			Message:     c.Message,
+			Bump:        changeBump(c.Change),
+			PullRequest: c.PullRequest,
+			Skipped:     semrel.IsSkipped(c),
+		}
+		if cr.Skipped {
+			cr.Bump = "none"
+		}
+		if c.Author != nil {
+			cr.Author = c.Author.Name
+			cr.Date = &c.Author.Date
+		}
+		p.Commits = append(p.Commits, cr)
+	}
+}
+
+func changeBump(change *semrel.Change) string {
+	switch {
+	case change == nil:
+		return "none"
+	case change.Major:
+		return "major"
+	case change.Minor:
+		return "minor"
+	case change.Patch:
+		return "patch"
+	}
+	return "none"
+}
+
+// versionBump returns the part of the version that changed from prev to next.
+func versionBump(prev, next string) string {
+	prevVer, err := semver.NewVersion(prev)
+	if err != nil {
+		return "none"
+	}
+	nextVer, err := semver.NewVersion(next)
+	if err != nil {
+		return "none"
+	}
+	switch {
+	case nextVer.Major() != prevVer.Major():
+		return "major"
+	case nextVer.Minor() != prevVer.Minor():
+		return "minor"
+	case nextVer.Patch() != prevVer.Patch():
+		return "patch"
+	case nextVer.Prerelease() != prevVer.Prerelease():
+		return "prerelease"
+	}
+	return "none"
+}
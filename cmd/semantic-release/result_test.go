@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionBump(t *testing.T) {
+	require.Equal(t, "major", versionBump("0.0.0", "1.0.0"))
+	require.Equal(t, "minor", versionBump("1.0.0", "1.1.0"))
+	require.Equal(t, "patch", versionBump("1.1.0", "1.1.1"))
+	require.Equal(t, "prerelease", versionBump("2.0.0-beta.1", "2.0.0-beta.2"))
+	require.Equal(t, "none", versionBump("1.0.0", ""))
+}
+
+func TestRunResult(t *testing.T) {
+	require := require.New(t)
+
+	result := &runResult{}
+	pkg := result.addPackage("api", false)
+	pkg.NewVersion = "1.1.0"
+	pkg.setCommits([]*semrel.Commit{
+		{SHA: "abcd", Type: "feat", Message: "new feature", Change: &semrel.Change{Minor: true}, PullRequest: 42},
+		{SHA: "bcde", Type: "feat", Message: "hidden", Raw: []string{"feat: hidden", "", "Release: skip"}, Change: &semrel.Change{Minor: true}},
+	})
+	result.finish(nil)
+
+	buf := &bytes.Buffer{}
+	require.NoError(result.write(buf))
+	var doc map[string]interface{}
+	require.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	require.Equal("1.1.0", doc["newVersion"])
+	require.NotContains(doc, "name")
+	require.NotContains(doc, "packages")
+	commit := doc["commits"].([]interface{})[0].(map[string]interface{})
+	require.Equal("minor", commit["bump"])
+	require.Equal(float64(42), commit["pullRequest"])
+	require.NotContains(commit, "skipped")
+	commit = doc["commits"].([]interface{})[1].(map[string]interface{})
+	require.Equal("none", commit["bump"])
+	require.Equal(true, commit["skipped"])
+
+	result = &runResult{}
+	result.addPackage("api", true)
+	result.addPackage("web", true)
+	result.setNoRelease(plugin.NoReleaseReasonNoChange, "")
+	result.finish(errors.New("no change"))
+	require.Nil(result.packageResult)
+	require.Len(result.Packages, 2)
+	require.Equal("no_change", result.NoReleaseReason)
+	require.Empty(result.Error)
+
+	result = &runResult{}
+	result.finish(errors.New("github token missing"))
+	require.Equal("github token missing", result.Error)
+
+	result = &runResult{}
+	result.setNoRelease(plugin.NoReleaseReasonFailure, "hook failed")
+	result.finish(errors.New("hook failed"))
+	require.Equal("failure", result.NoReleaseReason)
+	require.Equal("hook failed", result.Error)
+}
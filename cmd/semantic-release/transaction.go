@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// fileBackup is the content of a file before the release touched it.
+type fileBackup struct {
+	name    string
+	data    []byte
+	mode    os.FileMode
+	existed bool
+}
+
+// fileTransaction records the original state of every file that is changed
+// by a release so that the changes can be undone if the release fails.
+type fileTransaction struct {
+	backups []*fileBackup
+	seen    map[string]bool
+}
+
+func newFileTransaction() *fileTransaction {
+	return &fileTransaction{seen: make(map[string]bool)}
+}
+
+// track saves the current state of name, only the first call for a file
+// has an effect.
+func (t *fileTransaction) track(name string) error {
+	if t.seen[name] {
+		return nil
+	}
+	backup := &fileBackup{name: name}
+	info, err := os.Stat(name)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	default:
+		backup.data, err = ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		backup.mode = info.Mode()
+		backup.existed = true
+	}
+	t.seen[name] = true
+	t.backups = append(t.backups, backup)
+	return nil
+}
+
+// writeFile tracks name and writes data to it.
+func (t *fileTransaction) writeFile(name string, data []byte) error {
+	if err := t.track(name); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, data, 0644)
+}
+
+// rollback restores all tracked files, files that did not exist are removed.
+func (t *fileTransaction) rollback() error {
+	var firstErr error
+	for i := len(t.backups) - 1; i >= 0; i-- {
+		backup := t.backups[i]
+		var err error
+		if backup.existed {
+			err = ioutil.WriteFile(backup.name, backup.data, backup.mode)
+		} else if rerr := os.Remove(backup.name); rerr != nil && !os.IsNotExist(rerr) {
+			err = rerr
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("could not restore %s: %w", backup.name, err)
+		}
+	}
+	return firstErr
+}
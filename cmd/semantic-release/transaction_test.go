@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTransaction(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "transaction")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	existing := filepath.Join(dir, "CHANGELOG.md")
+	created := filepath.Join(dir, ".version")
+	require.NoError(ioutil.WriteFile(existing, []byte("old"), 0600))
+
+	tx := newFileTransaction()
+	require.NoError(tx.writeFile(existing, []byte("new")))
+	require.NoError(tx.writeFile(existing, []byte("newer")))
+	require.NoError(tx.writeFile(created, []byte("1.0.0")))
+	data, err := ioutil.ReadFile(existing)
+	require.NoError(err)
+	require.Equal("newer", string(data))
+
+	require.NoError(tx.rollback())
+	data, err = ioutil.ReadFile(existing)
+	require.NoError(err)
+	require.Equal("old", string(data))
+	info, err := os.Stat(existing)
+	require.NoError(err)
+	require.Equal(os.FileMode(0600), info.Mode().Perm())
+	_, err = os.Stat(created)
+	require.True(os.IsNotExist(err))
+}
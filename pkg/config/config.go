@@ -1,7 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -25,17 +29,195 @@ type Config struct {
 	HooksPlugins                    []string
 	HooksOpts                       map[string]string
 	UpdateFiles                     []string
+	Assets                          []string
 	Match                           string
+	TagFormat                       string
 	VersionFile                     bool
 	Prerelease                      bool
 	Ghr                             bool
 	NoCI                            bool
 	Dry                             bool
+	Snapshot                        bool
+	SnapshotTemplate                string
 	AllowInitialDevelopmentVersions bool
 	AllowNoChanges                  bool
 	ForceBumpPatchVersion           bool
 	MaintainedVersion               string
+	VersionScheme                   string
+	CalVerFormat                    string
 	PrependChangelog                bool
+	Output                          string
+	Packages                        []Package
+	Branches                        []Branch
+}
+
+// Package is an independently versioned package of a monorepo.
+type Package struct {
+	Name        string   `mapstructure:"name"`
+	Path        string   `mapstructure:"path"`
+	TagPrefix   string   `mapstructure:"tagPrefix"`
+	TagFormat   string   `mapstructure:"tagFormat"`
+	Changelog   string   `mapstructure:"changelog"`
+	UpdateFiles []string `mapstructure:"update"`
+	Assets      []string `mapstructure:"assets"`
+}
+
+// TagTemplate returns the tag format of the package, which defaults to the
+// tag prefix followed by the version.
+func (p Package) TagTemplate() string {
+	if p.TagFormat != "" {
+		return p.TagFormat
+	}
+	return p.TagPrefix + "{{.Version}}"
+}
+
+// loadPackages reads the packages from the config file and fills in the
+// defaults: the name is the path, the tag prefix is "<path>/v" and the
+// changelog file is placed in the package directory.
+func loadPackages(changelog string) ([]Package, error) {
+	var packages []Package
+	if err := viper.UnmarshalKey("packages", &packages); err != nil {
+		return nil, err
+	}
+	prefixes := make(map[string]bool)
+	for i := range packages {
+		pkg := &packages[i]
+		pkg.Path = strings.Trim(path.Clean("/"+pkg.Path), "/")
+		if pkg.Name == "" {
+			pkg.Name = pkg.Path
+		}
+		if pkg.Name == "" {
+			return nil, fmt.Errorf("package %d: name is required for the root package", i)
+		}
+		if pkg.TagPrefix == "" {
+			pkg.TagPrefix = "v"
+			if pkg.Path != "" {
+				pkg.TagPrefix = pkg.Path + "/v"
+			}
+		}
+		if prefixes[pkg.TagTemplate()] {
+			if pkg.TagFormat != "" {
+				return nil, fmt.Errorf("duplicate package tag format: %s", pkg.TagFormat)
+			}
+			return nil, fmt.Errorf("duplicate package tag prefix: %s", pkg.TagPrefix)
+		}
+		prefixes[pkg.TagTemplate()] = true
+		if pkg.Changelog == "" && changelog != "" {
+			pkg.Changelog = path.Join(pkg.Path, changelog)
+		}
+	}
+	return packages, nil
+}
+
+// Branch is a branch that releases are published from.
+type Branch struct {
+	// Name is the branch name or a path.Match pattern.
+	Name string
+	// Channel is the distribution channel of the releases, empty for the
+	// default channel.
+	Channel string
+	// Prerelease is the prerelease identifier of the versions released from
+	// a prerelease branch.
+	Prerelease string
+	// Range is the version range of a maintenance branch.
+	Range string
+}
+
+// Match reports whether the branch config applies to the branch name.
+func (b *Branch) Match(name string) bool {
+	if b.Name == name {
+		return true
+	}
+	ok, err := path.Match(b.Name, name)
+	return err == nil && ok
+}
+
+var maintenanceBranchRe = regexp.MustCompile(`^\d+(\.\d+)?\.x$`)
+
+// loadBranches reads the release branches from the config file. Every entry
+// is either a branch name or an object with name, channel, prerelease and
+// range. The first release branch publishes to the default channel, all other
+// branches to the channel named after the branch. Branches named like 1.x or
+// 1.2.x are maintenance branches of that range and "prerelease": true uses the
+// branch name as prerelease identifier.
+func loadBranches() ([]Branch, error) {
+	raw := viper.Get("branches")
+	if raw == nil {
+		return nil, nil
+	}
+	entries, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.New("branches must be a list")
+	}
+	branches := make([]Branch, 0, len(entries))
+	hasRelease := false
+	for i, entry := range entries {
+		b := Branch{}
+		channelSet := false
+		switch e := entry.(type) {
+		case string:
+			b.Name = e
+		case map[string]interface{}:
+			for key, value := range e {
+				var err error
+				switch strings.ToLower(key) {
+				case "name":
+					b.Name, err = branchOption(value)
+				case "channel":
+					b.Channel, err = branchOption(value)
+					channelSet = true
+				case "range":
+					b.Range, err = branchOption(value)
+				case "prerelease":
+					if enabled, isBool := value.(bool); isBool {
+						if enabled {
+							b.Prerelease = "true"
+						}
+						continue
+					}
+					b.Prerelease, err = branchOption(value)
+				default:
+					err = fmt.Errorf("unknown option %s", key)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("branch %d: %w", i, err)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("branch %d: must be a name or an object", i)
+		}
+		if b.Name == "" {
+			return nil, fmt.Errorf("branch %d: name is required", i)
+		}
+		if b.Prerelease == "true" {
+			b.Prerelease = b.Name
+		}
+		if b.Range == "" && b.Prerelease == "" && maintenanceBranchRe.MatchString(b.Name) {
+			b.Range = b.Name
+		}
+		if b.Range != "" && b.Prerelease != "" {
+			return nil, fmt.Errorf("branch %s: a maintenance branch can not be a prerelease branch", b.Name)
+		}
+		if !channelSet && (hasRelease || b.Range != "" || b.Prerelease != "") {
+			b.Channel = b.Name
+		}
+		if b.Range == "" && b.Prerelease == "" {
+			hasRelease = true
+		}
+		branches = append(branches, b)
+	}
+	if len(branches) > 0 && !hasRelease {
+		return nil, errors.New("at least one release branch without prerelease and range is required")
+	}
+	return branches, nil
+}
+
+func branchOption(value interface{}) (string, error) {
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid value %v", value)
+	}
+	return strings.TrimSpace(str), nil
 }
 
 func mustGetString(cmd *cobra.Command, name string) string {
@@ -77,6 +259,16 @@ func mergeOpts(v map[string]string, c []string) map[string]string {
 	return opts
 }
 
+// getAssets returns the --asset flags or, if none are given, the assets of the
+// config file. The flag is not bound to viper, as viper does not split string
+// arrays.
+func getAssets(cmd *cobra.Command) []string {
+	if cmd.Flags().Changed("asset") {
+		return mustGetStringArray(cmd, "asset")
+	}
+	return viper.GetStringSlice("assets")
+}
+
 func NewConfig(cmd *cobra.Command) (*Config, error) {
 	provOpts := mergeOpts(
 		viper.GetStringMapString("plugins.provider.options"),
@@ -97,6 +289,26 @@ func NewConfig(cmd *cobra.Command) (*Config, error) {
 		viper.GetStringMapString("plugins.hooks.options"),
 		mustGetStringArray(cmd, "hooks-opt"))
 
+	changelog := mustGetString(cmd, "changelog")
+	packages, err := loadPackages(changelog)
+	if err != nil {
+		return nil, err
+	}
+	assets := getAssets(cmd)
+	if len(packages) > 0 && len(assets) > 0 {
+		return nil, errors.New("the assets of a monorepo have to be configured per package")
+	}
+
+	branches, err := loadBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	output := mustGetString(cmd, "output")
+	if output != "text" && output != "json" {
+		return nil, fmt.Errorf("invalid output format: %s", output)
+	}
+
 	conf := &Config{
 		Token:                           mustGetString(cmd, "token"),
 		ProviderPlugin:                  viper.GetString("plugins.provider.name"),
@@ -107,23 +319,32 @@ func NewConfig(cmd *cobra.Command) (*Config, error) {
 		CIConditionOpts:                 ciOpts,
 		ChangelogGeneratorPlugin:        viper.GetString("plugins.changelog-generator.name"),
 		ChangelogGeneratorOpts:          cgOpts,
-		Changelog:                       mustGetString(cmd, "changelog"),
+		Changelog:                       changelog,
 		FilesUpdaterPlugins:             viper.GetStringSlice("plugins.files-updater.names"),
 		FilesUpdaterOpts:                fuOpts,
 		HooksPlugins:                    viper.GetStringSlice("plugins.hooks.names"),
 		HooksOpts:                       hoOpts,
 		UpdateFiles:                     mustGetStringArray(cmd, "update"),
+		Assets:                          assets,
 		Match:                           mustGetString(cmd, "match"),
+		TagFormat:                       viper.GetString("tagFormat"),
 		VersionFile:                     mustGetBool(cmd, "version-file"),
 		Prerelease:                      mustGetBool(cmd, "prerelease"),
 		Ghr:                             mustGetBool(cmd, "ghr"),
 		NoCI:                            mustGetBool(cmd, "no-ci"),
 		Dry:                             mustGetBool(cmd, "dry"),
+		Snapshot:                        mustGetBool(cmd, "snapshot"),
+		SnapshotTemplate:                mustGetString(cmd, "snapshot-template"),
 		AllowInitialDevelopmentVersions: mustGetBool(cmd, "allow-initial-development-versions"),
 		AllowNoChanges:                  mustGetBool(cmd, "allow-no-changes"),
 		ForceBumpPatchVersion:           mustGetBool(cmd, "force-bump-patch-version"),
 		MaintainedVersion:               viper.GetString("maintainedVersion"),
+		VersionScheme:                   viper.GetString("versionScheme"),
+		CalVerFormat:                    viper.GetString("calverFormat"),
 		PrependChangelog:                mustGetBool(cmd, "prepend-changelog"),
+		Output:                          output,
+		Packages:                        packages,
+		Branches:                        branches,
 	}
 	return conf, nil
 }
@@ -140,6 +361,12 @@ func detectCI() string {
 	if os.Getenv("GITLAB_CI") == "true" {
 		return "gitlab"
 	}
+	if os.Getenv("BITBUCKET_BUILD_NUMBER") != "" {
+		return "bitbucket"
+	}
+	if os.Getenv("TF_BUILD") == "True" {
+		return "azuredevops"
+	}
 	return "default"
 }
 
@@ -147,51 +374,64 @@ func defaultProvider() string {
 	if os.Getenv("GITLAB_CI") == "true" {
 		return "gitlab"
 	}
+	if os.Getenv("TF_BUILD") == "True" {
+		return "azuredevops"
+	}
 	return "github"
 }
 
 func InitConfig(cmd *cobra.Command) error {
-	cmd.Flags().StringP("token", "t", "", "provider token")
-	cmd.Flags().String("provider", defaultProvider(), "provider plugin name")
-	cmd.Flags().StringArray("provider-opt", []string{}, "options that are passed to the provider plugin")
-	cmd.Flags().String("commit-analyzer", "default", "commit-analyzer plugin name")
-	cmd.Flags().StringArray("commit-analyzer-opt", []string{}, "options that are passed to the commit-analyzer plugin")
-	cmd.Flags().String("ci-condition", detectCI(), "ci-condition plugin name")
-	cmd.Flags().StringArray("ci-condition-opt", []string{}, "options that are passed to the ci-condition plugin")
-	cmd.Flags().String("changelog-generator", "default", "changelog-generator plugin name")
-	cmd.Flags().StringArray("changelog-generator-opt", []string{}, "options that are passed to the changelog-generator plugin")
-	cmd.Flags().String("changelog", "", "creates a changelog file")
-	cmd.Flags().StringSlice("files-updater", []string{"npm"}, "files-updater plugin names")
-	cmd.Flags().StringArray("files-updater-opt", []string{}, "options that are passed to the files-updater plugins")
-	cmd.Flags().StringSlice("hooks", []string{}, "hooks plugin names")
-	cmd.Flags().StringArray("hooks-opt", []string{}, "options that are passed to the hooks plugins")
-	cmd.Flags().StringArrayP("update", "u", []string{}, "updates the version of a certain files")
-	cmd.Flags().String("match", "", "only consider tags matching the given glob(7) pattern, excluding the \"refs/tags/\" prefix.")
-	cmd.Flags().String("maintained-version", "", "set the maintained version as base for new releases")
-	cmd.Flags().BoolP("version-file", "f", false, "create a .version file with the new version")
-	cmd.Flags().Bool("prerelease", false, "flags the release as a prerelease")
-	cmd.Flags().Bool("ghr", false, "create a .ghr file with the parameters for ghr")
-	cmd.Flags().Bool("no-ci", false, "run semantic-release locally")
-	cmd.Flags().Bool("dry", false, "do not create release")
-	cmd.Flags().Bool("allow-initial-development-versions", false, "semantic-release will start your initial development release at 0.1.0")
-	cmd.Flags().Bool("allow-no-changes", false, "exit with code 0 if no changes are found, useful if semantic-release is automatically run")
-	cmd.Flags().Bool("force-bump-patch-version", false, "increments the patch version if no changes are found")
-	cmd.Flags().Bool("prepend-changelog", false, "if the changelog file already exist the new changelog is prepended")
-	cmd.Flags().SortFlags = true
+	cmd.PersistentFlags().StringP("token", "t", "", "provider token")
+	cmd.PersistentFlags().String("provider", defaultProvider(), "provider plugin name")
+	cmd.PersistentFlags().StringArray("provider-opt", []string{}, "options that are passed to the provider plugin")
+	cmd.PersistentFlags().String("commit-analyzer", "default", "commit-analyzer plugin name")
+	cmd.PersistentFlags().StringArray("commit-analyzer-opt", []string{}, "options that are passed to the commit-analyzer plugin")
+	cmd.PersistentFlags().String("ci-condition", detectCI(), "ci-condition plugin name")
+	cmd.PersistentFlags().StringArray("ci-condition-opt", []string{}, "options that are passed to the ci-condition plugin")
+	cmd.PersistentFlags().String("changelog-generator", "default", "changelog-generator plugin name")
+	cmd.PersistentFlags().StringArray("changelog-generator-opt", []string{}, "options that are passed to the changelog-generator plugin")
+	cmd.PersistentFlags().String("changelog", "", "creates a changelog file")
+	cmd.PersistentFlags().StringSlice("files-updater", []string{"npm"}, "files-updater plugin names")
+	cmd.PersistentFlags().StringArray("files-updater-opt", []string{}, "options that are passed to the files-updater plugins")
+	cmd.PersistentFlags().StringSlice("hooks", []string{}, "hooks plugin names")
+	cmd.PersistentFlags().StringArray("hooks-opt", []string{}, "options that are passed to the hooks plugins")
+	cmd.PersistentFlags().StringArrayP("update", "u", []string{}, "updates the version of a certain files")
+	cmd.PersistentFlags().StringArray("asset", []string{}, "glob pattern of files that are uploaded as release assets")
+	cmd.PersistentFlags().String("match", "", "only consider tags matching the given glob(7) pattern, excluding the \"refs/tags/\" prefix.")
+	cmd.PersistentFlags().String("tag-format", "v{{.Version}}", "template of the tag names of releases")
+	cmd.PersistentFlags().String("maintained-version", "", "set the maintained version as base for new releases")
+	cmd.PersistentFlags().String("version-scheme", "semver", "version scheme of the releases, semver or calver")
+	cmd.PersistentFlags().String("calver-format", "YYYY.MM.MICRO", "format of calendar versions, made of YYYY, YY, 0Y, MM, 0M, WW, 0W, DD, 0D and MICRO")
+	cmd.PersistentFlags().BoolP("version-file", "f", false, "create a .version file with the new version")
+	cmd.PersistentFlags().Bool("prerelease", false, "flags the release as a prerelease")
+	cmd.PersistentFlags().Bool("ghr", false, "create a .ghr file with the parameters for ghr")
+	cmd.PersistentFlags().Bool("no-ci", false, "run semantic-release locally")
+	cmd.PersistentFlags().Bool("dry", false, "do not create release")
+	cmd.PersistentFlags().Bool("snapshot", false, "calculate a snapshot version of an unreleased build, write it and update the files without creating a release")
+	cmd.PersistentFlags().String("snapshot-template", "", "template of the snapshot version with the fields Version, Commits, Date, SHA and ShortSHA (default \"{{.Version}}-snapshot.{{.Date}}.{{.Commits}}+sha.{{.ShortSHA}}\")")
+	cmd.PersistentFlags().Bool("allow-initial-development-versions", false, "semantic-release will start your initial development release at 0.1.0")
+	cmd.PersistentFlags().Bool("allow-no-changes", false, "exit with code 0 if no changes are found, useful if semantic-release is automatically run")
+	cmd.PersistentFlags().Bool("force-bump-patch-version", false, "increments the patch version if no changes are found")
+	cmd.PersistentFlags().Bool("prepend-changelog", false, "if the changelog file already exist the new changelog is prepended")
+	cmd.PersistentFlags().String("output", "text", "output format of the result, text or json (printed to stdout)")
+	cmd.PersistentFlags().SortFlags = true
 
 	viper.AddConfigPath(".")
 	viper.SetConfigName(".semrelrc")
 	viper.SetConfigType("json")
 
-	must(viper.BindPFlag("maintainedVersion", cmd.Flags().Lookup("maintained-version")))
+	must(viper.BindPFlag("maintainedVersion", cmd.PersistentFlags().Lookup("maintained-version")))
 	must(viper.BindEnv("maintainedVersion", "MAINTAINED_VERSION"))
+	must(viper.BindPFlag("tagFormat", cmd.PersistentFlags().Lookup("tag-format")))
+	must(viper.BindPFlag("versionScheme", cmd.PersistentFlags().Lookup("version-scheme")))
+	must(viper.BindPFlag("calverFormat", cmd.PersistentFlags().Lookup("calver-format")))
 
-	must(viper.BindPFlag("plugins.provider.name", cmd.Flags().Lookup("provider")))
-	must(viper.BindPFlag("plugins.commit-analyzer.name", cmd.Flags().Lookup("commit-analyzer")))
-	must(viper.BindPFlag("plugins.ci-condition.name", cmd.Flags().Lookup("ci-condition")))
-	must(viper.BindPFlag("plugins.changelog-generator.name", cmd.Flags().Lookup("changelog-generator")))
-	must(viper.BindPFlag("plugins.files-updater.names", cmd.Flags().Lookup("files-updater")))
-	must(viper.BindPFlag("plugins.hooks.names", cmd.Flags().Lookup("hooks")))
+	must(viper.BindPFlag("plugins.provider.name", cmd.PersistentFlags().Lookup("provider")))
+	must(viper.BindPFlag("plugins.commit-analyzer.name", cmd.PersistentFlags().Lookup("commit-analyzer")))
+	must(viper.BindPFlag("plugins.ci-condition.name", cmd.PersistentFlags().Lookup("ci-condition")))
+	must(viper.BindPFlag("plugins.changelog-generator.name", cmd.PersistentFlags().Lookup("changelog-generator")))
+	must(viper.BindPFlag("plugins.files-updater.names", cmd.PersistentFlags().Lookup("files-updater")))
+	must(viper.BindPFlag("plugins.hooks.names", cmd.PersistentFlags().Lookup("hooks")))
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
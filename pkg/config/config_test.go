@@ -0,0 +1,100 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPackages(t *testing.T) {
+	defer viper.Reset()
+	require := require.New(t)
+
+	viper.Set("packages", []map[string]interface{}{
+		{"path": "./api/"},
+		{"name": "web", "path": "frontend/web", "tagPrefix": "web@", "changelog": "CHANGES.md", "update": []string{"package.json"}, "assets": []string{"dist/*.zip"}},
+		{"name": "root", "path": "."},
+	})
+	packages, err := loadPackages("CHANGELOG.md")
+	require.NoError(err)
+	require.Equal([]Package{
+		{Name: "api", Path: "api", TagPrefix: "api/v", Changelog: "api/CHANGELOG.md"},
+		{Name: "web", Path: "frontend/web", TagPrefix: "web@", Changelog: "CHANGES.md", UpdateFiles: []string{"package.json"}, Assets: []string{"dist/*.zip"}},
+		{Name: "root", Path: "", TagPrefix: "v", Changelog: "CHANGELOG.md"},
+	}, packages)
+
+	viper.Set("packages", []map[string]interface{}{{"path": "."}})
+	_, err = loadPackages("")
+	require.EqualError(err, "package 0: name is required for the root package")
+
+	viper.Set("packages", []map[string]interface{}{{"path": "api"}, {"path": "web", "tagPrefix": "api/v"}})
+	_, err = loadPackages("")
+	require.EqualError(err, "duplicate package tag prefix: api/v")
+
+	viper.Set("packages", []map[string]interface{}{{"path": "api"}, {"path": "web", "tagFormat": "api/v{{.Version}}"}})
+	_, err = loadPackages("")
+	require.EqualError(err, "duplicate package tag format: api/v{{.Version}}")
+}
+
+func TestLoadBranches(t *testing.T) {
+	defer viper.Reset()
+	require := require.New(t)
+
+	branches, err := loadBranches()
+	require.NoError(err)
+	require.Nil(branches)
+
+	viper.Set("branches", []interface{}{
+		"main",
+		map[string]interface{}{"name": "next"},
+		map[string]interface{}{"name": "beta", "prerelease": true},
+		map[string]interface{}{"name": "alpha", "prerelease": "a", "channel": "unstable"},
+		"1.x",
+		map[string]interface{}{"name": "release/*", "range": "2.1.x"},
+	})
+	branches, err = loadBranches()
+	require.NoError(err)
+	require.Equal([]Branch{
+		{Name: "main"},
+		{Name: "next", Channel: "next"},
+		{Name: "beta", Channel: "beta", Prerelease: "beta"},
+		{Name: "alpha", Channel: "unstable", Prerelease: "a"},
+		{Name: "1.x", Channel: "1.x", Range: "1.x"},
+		{Name: "release/*", Channel: "release/*", Range: "2.1.x"},
+	}, branches)
+	require.True(branches[5].Match("release/2.1"))
+	require.False(branches[5].Match("main"))
+
+	viper.Set("branches", []interface{}{map[string]interface{}{"name": "beta", "prerelease": true}})
+	_, err = loadBranches()
+	require.EqualError(err, "at least one release branch without prerelease and range is required")
+
+	viper.Set("branches", []interface{}{"main", map[string]interface{}{"channel": "next"}})
+	_, err = loadBranches()
+	require.EqualError(err, "branch 1: name is required")
+
+	viper.Set("branches", []interface{}{"main", map[string]interface{}{"name": "1.x", "range": "1.x", "prerelease": "rc"}})
+	_, err = loadBranches()
+	require.EqualError(err, "branch 1.x: a maintenance branch can not be a prerelease branch")
+}
+
+func TestGetAssets(t *testing.T) {
+	defer viper.Reset()
+
+	cmd := &cobra.Command{}
+	require.NoError(t, InitConfig(cmd))
+	require.Empty(t, getAssets(cmd))
+
+	viper.Set("assets", []string{"dist/*.zip"})
+	require.Equal(t, []string{"dist/*.zip"}, getAssets(cmd))
+
+	require.NoError(t, cmd.ParseFlags([]string{"--asset", "a.tar.gz", "--asset", "b,c.txt"}))
+	require.Equal(t, []string{"a.tar.gz", "b,c.txt"}, getAssets(cmd))
+
+	// monorepo packages have their own assets
+	viper.Set("packages", []map[string]interface{}{{"path": "api"}})
+	_, err := NewConfig(cmd)
+	require.EqualError(t, err, "the assets of a monorepo have to be configured per package")
+}
@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// ExpandAssets resolves the glob patterns of the release assets to a sorted list of unique files.
+func ExpandAssets(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("asset pattern %s did not match any files", pattern)
+		}
+		for _, m := range matches {
+			if seen[m] {
+				continue
+			}
+			seen[m] = true
+			files = append(files, m)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// CheckAssetNames returns an error if several assets would be published under
+// the same file name. The reserved names are used by files that the provider
+// adds to the assets itself.
+func CheckAssetNames(files []string, reserved ...string) error {
+	names := make(map[string]string)
+	for _, name := range reserved {
+		if name != "" {
+			names[name] = name
+		}
+	}
+	for _, file := range files {
+		name := filepath.Base(file)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("assets %s and %s have the same name %s", other, file, name)
+		}
+		names[name] = file
+	}
+	return nil
+}
@@ -1,5 +1,10 @@
 package plugin
 
+import (
+	"path"
+	"strings"
+)
+
 type CICondition interface {
 	Name() string
 	Version() string
@@ -15,3 +20,27 @@ var (
 func RegisterCICondition(cc CICondition) {
 	ciConditionSet[cc.Name()] = cc
 }
+
+// ReleaseBranches returns the branches that are allowed to publish releases.
+// The CLI passes the configured release branches as comma separated list in
+// "releaseBranches", the "defaultBranch" is used if the list is missing.
+func ReleaseBranches(config map[string]string) []string {
+	if branches := config["releaseBranches"]; branches != "" {
+		return strings.Split(branches, ",")
+	}
+	return []string{config["defaultBranch"]}
+}
+
+// MatchBranch reports whether branch matches one of the patterns. The
+// patterns use the syntax of path.Match, "*" matches every branch.
+func MatchBranch(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == branch {
+			return true
+		}
+		if ok, err := path.Match(pattern, branch); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,7 @@
 package plugin
 
+import "fmt"
+
 type pluginType string
 
 const (
@@ -16,8 +18,22 @@ type NoReleaseReason int32
 const (
 	NoReleaseReasonCondition NoReleaseReason = 0
 	NoReleaseReasonNoChange  NoReleaseReason = 1
+	// NoReleaseReasonFailure is used if a release failed and was rolled back.
+	NoReleaseReasonFailure NoReleaseReason = 2
 )
 
+func (r NoReleaseReason) String() string {
+	switch r {
+	case NoReleaseReasonCondition:
+		return "condition"
+	case NoReleaseReasonNoChange:
+		return "no_change"
+	case NoReleaseReasonFailure:
+		return "failure"
+	}
+	return fmt.Sprintf("NoReleaseReason(%d)", int32(r))
+}
+
 type RepositoryInfo struct {
 	Owner         string
 	Repo          string
@@ -28,7 +44,18 @@ type RepositoryInfo struct {
 type CreateReleaseConfig struct {
 	Changelog  string
 	NewVersion string
+	Tag        string
 	Prerelease bool
 	Branch     string
 	SHA        string
+	Assets     []string
+}
+
+// TagName returns the tag of the release, which defaults to the new version
+// prefixed with "v".
+func (c *CreateReleaseConfig) TagName() string {
+	if c.Tag != "" {
+		return c.Tag
+	}
+	return "v" + c.NewVersion
 }
@@ -12,6 +12,9 @@ type Provider interface {
 	GetCommits(fromSha, toSha string) ([]*semrel.RawCommit, error)
 	GetReleases(re string) ([]*semrel.Release, error)
 	CreateRelease(*CreateReleaseConfig) error
+	// DeleteRelease removes the release and the tag created by CreateRelease,
+	// parts that do not exist are ignored.
+	DeleteRelease(*CreateReleaseConfig) error
 }
 
 var (
@@ -0,0 +1,227 @@
+package semrel
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duanqy/semantic-release/pkg/config"
+)
+
+// DefaultCalVerFormat is the format of calendar versions if none is configured.
+const DefaultCalVerFormat = "YYYY.MM.MICRO"
+
+// now returns the date that calendar versions are based on.
+var now = time.Now
+
+// calverTokens are the supported parts of a calendar version format, see
+// https://calver.org. The tokens with a leading 0 are zero-padded.
+var calverTokens = map[string]bool{
+	"YYYY": true, "YY": true, "0Y": true,
+	"MM": true, "0M": true,
+	"WW": true, "0W": true,
+	"DD": true, "0D": true,
+	"MICRO": true,
+}
+
+// calverScheme implements calendar versioning. A version consists of the
+// dot separated parts of the format and may have a prerelease and build
+// metadata suffix like a semantic version.
+type calverScheme struct {
+	format []string
+}
+
+func newCalVerScheme(format string) (*calverScheme, error) {
+	if format == "" {
+		format = DefaultCalVerFormat
+	}
+	tokens := strings.Split(format, ".")
+	hasDate := false
+	for i, token := range tokens {
+		if !calverTokens[token] {
+			return nil, fmt.Errorf("invalid calver format %s: unknown part %s", format, token)
+		}
+		if token == "MICRO" && i != len(tokens)-1 {
+			return nil, fmt.Errorf("invalid calver format %s: MICRO has to be the last part", format)
+		}
+		hasDate = hasDate || token != "MICRO"
+	}
+	if !hasDate {
+		return nil, fmt.Errorf("invalid calver format %s: a date part is required", format)
+	}
+	return &calverScheme{format: tokens}, nil
+}
+
+func (s *calverScheme) Name() string {
+	return "calver"
+}
+
+type calverVersion struct {
+	parts      []int
+	prerelease string
+	build      string
+}
+
+func (s *calverScheme) parse(version string) (*calverVersion, error) {
+	v := &calverVersion{}
+	core := version
+	if i := strings.Index(core, "+"); i >= 0 {
+		core, v.build = core[:i], core[i+1:]
+	}
+	if i := strings.Index(core, "-"); i >= 0 {
+		core, v.prerelease = core[:i], core[i+1:]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) != len(s.format) {
+		return nil, fmt.Errorf("version %s does not match the calver format %s", version, strings.Join(s.format, "."))
+	}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("version %s does not match the calver format %s", version, strings.Join(s.format, "."))
+		}
+		v.parts = append(v.parts, n)
+	}
+	return v, nil
+}
+
+func (s *calverScheme) formatVersion(v *calverVersion) string {
+	parts := make([]string, len(v.parts))
+	for i, n := range v.parts {
+		if strings.HasPrefix(s.format[i], "0") {
+			parts[i] = fmt.Sprintf("%02d", n)
+		} else {
+			parts[i] = strconv.Itoa(n)
+		}
+	}
+	version := strings.Join(parts, ".")
+	if v.prerelease != "" {
+		version += "-" + v.prerelease
+	}
+	if v.build != "" {
+		version += "+" + v.build
+	}
+	return version
+}
+
+func (s *calverScheme) Parse(version string) (string, error) {
+	v, err := s.parse(version)
+	if err != nil {
+		return "", err
+	}
+	return s.formatVersion(v), nil
+}
+
+func (s *calverScheme) Compare(a, b string) int {
+	va, errA := s.parse(a)
+	vb, errB := s.parse(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	for i := range va.parts {
+		if va.parts[i] != vb.parts[i] {
+			if va.parts[i] < vb.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	// a prerelease precedes the release of the same version
+	switch {
+	case va.prerelease == vb.prerelease:
+		return 0
+	case va.prerelease == "":
+		return 1
+	case vb.prerelease == "":
+		return -1
+	}
+	return strings.Compare(va.prerelease, vb.prerelease)
+}
+
+func (s *calverScheme) IsPrerelease(version string) bool {
+	v, err := s.parse(version)
+	return err == nil && v.prerelease != ""
+}
+
+var errCalVerBranch = errors.New("the calver version scheme does not support prerelease and maintenance branches")
+
+func (s *calverScheme) LatestRelease(releases []*Release, branch *config.Branch) (*Release, error) {
+	if branch.Prerelease != "" || branch.Range != "" {
+		return nil, errCalVerBranch
+	}
+	var latest *Release
+	for _, r := range releases {
+		if s.IsPrerelease(r.Version) {
+			continue
+		}
+		if _, err := s.parse(r.Version); err != nil {
+			continue
+		}
+		if latest == nil || s.Compare(r.Version, latest.Version) > 0 {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return &Release{SHA: "", Version: s.formatVersion(&calverVersion{parts: make([]int, len(s.format))})}, nil
+	}
+	return latest, nil
+}
+
+// datePart returns the value of a date token for t.
+func datePart(token string, t time.Time) int {
+	switch token {
+	case "YYYY":
+		return t.Year()
+	case "YY", "0Y":
+		return t.Year() % 100
+	case "MM", "0M":
+		return int(t.Month())
+	case "WW", "0W":
+		_, week := t.ISOWeek()
+		return week
+	}
+	return t.Day()
+}
+
+// NextVersion returns the version of the current date. The micro part is
+// incremented if the latest release belongs to the same period. The change
+// level only decides whether a release is created.
+func (s *calverScheme) NextVersion(conf *config.Config, branch *config.Branch, change *Change, latestRelease *Release, allReleases []*Release) (string, error) {
+	if branch.Prerelease != "" || branch.Range != "" {
+		return "", errCalVerBranch
+	}
+	if !change.Major && !change.Minor && !change.Patch && !conf.ForceBumpPatchVersion {
+		return "", nil
+	}
+	today := now().UTC()
+	next := &calverVersion{parts: make([]int, len(s.format))}
+	for i, token := range s.format {
+		if token != "MICRO" {
+			next.parts[i] = datePart(token, today)
+		}
+	}
+
+	latest, err := s.parse(latestRelease.Version)
+	if err == nil && samePeriod(s.format, latest, next) {
+		if s.format[len(s.format)-1] != "MICRO" {
+			return "", fmt.Errorf("version %s was already released in this period", latestRelease.Version)
+		}
+		next.parts[len(next.parts)-1] = latest.parts[len(latest.parts)-1] + 1
+	}
+	newVersion := s.formatVersion(next)
+	if err == nil && s.Compare(newVersion, latestRelease.Version) <= 0 {
+		return "", fmt.Errorf("version %s is not greater than the latest release %s", newVersion, latestRelease.Version)
+	}
+	return newVersion, nil
+}
+
+func samePeriod(format []string, a, b *calverVersion) bool {
+	for i, token := range format {
+		if token != "MICRO" && a.parts[i] != b.parts[i] {
+			return false
+		}
+	}
+	return true
+}
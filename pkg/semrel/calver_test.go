@@ -0,0 +1,122 @@
+package semrel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVersionScheme(t *testing.T) {
+	scheme, err := NewVersionScheme("", "")
+	require.NoError(t, err)
+	require.Equal(t, "semver", scheme.Name())
+
+	scheme, err = NewVersionScheme("calver", "")
+	require.NoError(t, err)
+	require.Equal(t, "calver", scheme.Name())
+
+	_, err = NewVersionScheme("romver", "")
+	require.EqualError(t, err, "unknown version scheme: romver")
+
+	for _, format := range []string{"YYYY.MONTH", "YYYY.MICRO.MM", "MICRO"} {
+		_, err = NewVersionScheme("calver", format)
+		require.Error(t, err, format)
+	}
+}
+
+func TestCalVerParse(t *testing.T) {
+	scheme, err := newCalVerScheme("YY.0M.MICRO")
+	require.NoError(t, err)
+
+	version, err := scheme.Parse("26.1.3")
+	require.NoError(t, err)
+	require.Equal(t, "26.01.3", version)
+
+	version, err = scheme.Parse("26.10.0-rc.1+build")
+	require.NoError(t, err)
+	require.Equal(t, "26.10.0-rc.1+build", version)
+	require.True(t, scheme.IsPrerelease(version))
+
+	_, err = scheme.Parse("1.2.3.4")
+	require.Error(t, err)
+	_, err = scheme.Parse("26.x.0")
+	require.Error(t, err)
+
+	require.Equal(t, -1, scheme.Compare("26.09.5", "26.10.0"))
+	require.Equal(t, 1, scheme.Compare("26.10.0", "26.10.0-rc.1"))
+	require.Equal(t, 0, scheme.Compare("26.10.0", "26.10.0"))
+}
+
+func TestCalVerLatestRelease(t *testing.T) {
+	scheme, err := newCalVerScheme("")
+	require.NoError(t, err)
+	branch := &config.Branch{Name: "main"}
+
+	latest, err := scheme.LatestRelease(nil, branch)
+	require.NoError(t, err)
+	require.Equal(t, "0.0.0", latest.Version)
+
+	releases := []*Release{
+		{SHA: "a", Version: "2026.9.4"},
+		{SHA: "b", Version: "2026.10.1"},
+		{SHA: "c", Version: "2026.10.2-rc.1"},
+		{SHA: "d", Version: "2026.10.0"},
+	}
+	latest, err = scheme.LatestRelease(releases, branch)
+	require.NoError(t, err)
+	require.Equal(t, "b", latest.SHA)
+
+	_, err = scheme.LatestRelease(releases, &config.Branch{Name: "beta", Prerelease: "beta"})
+	require.Equal(t, errCalVerBranch, err)
+}
+
+func TestCalVerNextVersion(t *testing.T) {
+	defer func() { now = time.Now }()
+	now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+
+	scheme, err := newCalVerScheme("")
+	require.NoError(t, err)
+	conf := &config.Config{}
+	branch := &config.Branch{Name: "main"}
+	feature := &Change{Minor: true}
+
+	testCases := []struct {
+		latest  string
+		change  *Change
+		version string
+	}{
+		{"0.0.0", feature, "2026.10.0"},
+		{"2026.9.7", feature, "2026.10.0"},
+		{"2026.10.0", &Change{Patch: true}, "2026.10.1"},
+		{"2026.10.4", &Change{Major: true}, "2026.10.5"},
+		{"2026.10.4", &Change{}, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.latest, func(t *testing.T) {
+			version, err := scheme.NextVersion(conf, branch, tc.change, &Release{Version: tc.latest}, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.version, version)
+		})
+	}
+
+	_, err = scheme.NextVersion(conf, branch, feature, &Release{Version: "2026.11.0"}, nil)
+	require.EqualError(t, err, "version 2026.10.0 is not greater than the latest release 2026.11.0")
+
+	daily, err := newCalVerScheme("YYYY.0M.0D")
+	require.NoError(t, err)
+	version, err := daily.NextVersion(conf, branch, feature, &Release{Version: "2026.10.17"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "2026.10.18", version)
+	_, err = daily.NextVersion(conf, branch, feature, &Release{Version: "2026.10.18"}, nil)
+	require.EqualError(t, err, "version 2026.10.18 was already released in this period")
+
+	_, err = scheme.NextVersion(conf, &config.Branch{Name: "1.x", Range: "1.x"}, feature, &Release{Version: "0.0.0"}, nil)
+	require.Equal(t, errCalVerBranch, err)
+
+	// GetNewVersion selects the scheme from the config
+	version, err = GetNewVersion(&config.Config{VersionScheme: "calver"}, branch, []*Commit{{SHA: "a", Change: feature}}, &Release{Version: "2026.10.3"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "2026.10.4", version)
+}
@@ -0,0 +1,70 @@
+package semrel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SkipReleaseMarker excludes a commit from the version calculation if it is
+// part of the commit message.
+const SkipReleaseMarker = "[skip release]"
+
+var (
+	releaseAsPattern   = regexp.MustCompile(`(?i)^Release-As:\s*(\S+)\s*$`)
+	releaseSkipPattern = regexp.MustCompile(`(?i)^Release:\s*skip\s*$`)
+)
+
+// IsSkipped reports whether commit is excluded from the version calculation
+// by a "[skip release]" marker or a "Release: skip" footer.
+func IsSkipped(commit *Commit) bool {
+	for _, line := range commit.Raw {
+		if strings.Contains(strings.ToLower(line), SkipReleaseMarker) || releaseSkipPattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseAs returns the version of the "Release-As" footer of commit or an
+// empty string.
+func ReleaseAs(commit *Commit) string {
+	for i := len(commit.Raw) - 1; i > 0; i-- {
+		if found := releaseAsPattern.FindStringSubmatch(commit.Raw[i]); found != nil {
+			return found[1]
+		}
+	}
+	return ""
+}
+
+// ReleaseAsCommit returns the latest commit since latestRelease that pins the
+// version of the next release with a "Release-As" footer. Skipped commits are
+// ignored.
+func ReleaseAsCommit(commits []*Commit, latestRelease *Release) *Commit {
+	for _, commit := range commits {
+		if latestRelease.SHA == commit.SHA {
+			break
+		}
+		if !IsSkipped(commit) && ReleaseAs(commit) != "" {
+			return commit
+		}
+	}
+	return nil
+}
+
+// pinnedVersion validates the "Release-As" version of commit, it has to be
+// greater than the latest release.
+func pinnedVersion(scheme VersionScheme, commit *Commit, latestRelease *Release) (string, error) {
+	version, err := scheme.Parse(ReleaseAs(commit))
+	if err != nil {
+		return "", fmt.Errorf("invalid Release-As version %s of commit %s: %w", ReleaseAs(commit), commit.SHA, err)
+	}
+	latest, err := scheme.Parse(latestRelease.Version)
+	if err != nil {
+		return "", err
+	}
+	if scheme.Compare(version, latest) <= 0 {
+		return "", fmt.Errorf("version %s of the Release-As footer of commit %s is not greater than the latest release %s", version, commit.SHA, latest)
+	}
+	return version, nil
+}
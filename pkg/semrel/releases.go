@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/duanqy/semantic-release/pkg/config"
 )
 
 type releases []*Release
@@ -78,3 +79,48 @@ func (r releases) GetLatestRelease(vrange string) (*Release, error) {
 func GetLatestReleaseFromReleases(rawReleases []*Release, vrange string) (*Release, error) {
 	return releases(rawReleases).GetLatestRelease(vrange)
 }
+
+// GetLatestBranchRelease returns the release that the next release of branch
+// is based on, according to the version scheme of conf.
+func GetLatestBranchRelease(conf *config.Config, rawReleases []*Release, branch *config.Branch) (*Release, error) {
+	scheme, err := NewVersionScheme(conf.VersionScheme, conf.CalVerFormat)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.LatestRelease(rawReleases, branch)
+}
+
+// semverLatestRelease returns the latest semantic version release of branch.
+// A maintenance branch continues the latest release of its range and a
+// prerelease branch its latest prerelease, as long as it is newer than the
+// latest stable release.
+func semverLatestRelease(rawReleases []*Release, branch *config.Branch) (*Release, error) {
+	if branch.Range != "" {
+		return GetLatestReleaseFromReleases(rawReleases, branch.Range)
+	}
+	latest, err := GetLatestReleaseFromReleases(rawReleases, "")
+	if err != nil || branch.Prerelease == "" {
+		return latest, err
+	}
+	latestVersion := semver.MustParse(latest.Version)
+	var prerelease *Release
+	var prereleaseVersion *semver.Version
+	for _, r := range rawReleases {
+		version := semver.MustParse(r.Version)
+		if prereleaseID(version) != branch.Prerelease || !version.GreaterThan(latestVersion) {
+			continue
+		}
+		if prerelease == nil || version.GreaterThan(prereleaseVersion) {
+			prerelease, prereleaseVersion = r, version
+		}
+	}
+	if prerelease != nil {
+		return prerelease, nil
+	}
+	return latest, nil
+}
+
+// prereleaseID returns the identifier of a prerelease version without the counter.
+func prereleaseID(version *semver.Version) string {
+	return strings.SplitN(version.Prerelease(), ".", 2)[0]
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/duanqy/semantic-release/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -77,3 +78,32 @@ func TestReleases(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLatestBranchRelease(t *testing.T) {
+	rawReleases := []*Release{
+		{SHA: "a", Version: "1.0.0"},
+		{SHA: "b", Version: "1.1.0"},
+		{SHA: "c", Version: "2.0.0"},
+		{SHA: "d", Version: "2.1.0-beta.1"},
+		{SHA: "e", Version: "2.1.0-beta.2"},
+		{SHA: "f", Version: "1.5.0-alpha.1"},
+	}
+	testCases := []struct {
+		branch        *config.Branch
+		LatestVersion string
+	}{
+		{&config.Branch{Name: "main"}, "2.0.0"},
+		{&config.Branch{Name: "beta", Prerelease: "beta"}, "2.1.0-beta.2"},
+		// prereleases that are older than the latest release are not continued
+		{&config.Branch{Name: "alpha", Prerelease: "alpha"}, "2.0.0"},
+		{&config.Branch{Name: "rc", Prerelease: "rc"}, "2.0.0"},
+		{&config.Branch{Name: "1.x", Range: "1.x"}, "1.1.0"},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Branch: %s, LV: %s", tc.branch.Name, tc.LatestVersion), func(t *testing.T) {
+			lr, err := GetLatestBranchRelease(&config.Config{}, rawReleases, tc.branch)
+			require.NoError(t, err)
+			require.Equal(t, tc.LatestVersion, lr.Version)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package semrel
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/duanqy/semantic-release/pkg/config"
+)
+
+// VersionScheme defines how versions are parsed, ordered and incremented.
+type VersionScheme interface {
+	// Name returns the name that selects the scheme in the config.
+	Name() string
+	// Parse validates version and returns it in canonical form.
+	Parse(version string) (string, error)
+	// Compare returns -1, 0 or 1 if the canonical version a is lower than,
+	// equal to or greater than b.
+	Compare(a, b string) int
+	// IsPrerelease reports whether the canonical version is a prerelease.
+	IsPrerelease(version string) bool
+	// LatestRelease returns the release that the next release of branch is
+	// based on.
+	LatestRelease(releases []*Release, branch *config.Branch) (*Release, error)
+	// NextVersion returns the version following latestRelease for change, it
+	// is empty if change requires no release.
+	NextVersion(conf *config.Config, branch *config.Branch, change *Change, latestRelease *Release, allReleases []*Release) (string, error)
+}
+
+// NewVersionScheme returns the version scheme called name, semver is used if
+// name is empty. The format is only used by the calver scheme.
+func NewVersionScheme(name, format string) (VersionScheme, error) {
+	switch name {
+	case "", "semver":
+		return &semverScheme{}, nil
+	case "calver":
+		return newCalVerScheme(format)
+	}
+	return nil, fmt.Errorf("unknown version scheme: %s", name)
+}
+
+type semverScheme struct{}
+
+func (s *semverScheme) Name() string {
+	return "semver"
+}
+
+func (s *semverScheme) Parse(version string) (string, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+func (s *semverScheme) Compare(a, b string) int {
+	return semver.MustParse(a).Compare(semver.MustParse(b))
+}
+
+func (s *semverScheme) IsPrerelease(version string) bool {
+	return semver.MustParse(version).Prerelease() != ""
+}
+
+func (s *semverScheme) LatestRelease(releases []*Release, branch *config.Branch) (*Release, error) {
+	return semverLatestRelease(releases, branch)
+}
+
+func (s *semverScheme) NextVersion(conf *config.Config, branch *config.Branch, change *Change, latestRelease *Release, allReleases []*Release) (string, error) {
+	return semverNextVersion(conf, branch, change, latestRelease, allReleases)
+}
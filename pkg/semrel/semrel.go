@@ -15,6 +15,9 @@ func calculateChange(commits []*Commit, latestRelease *Release) *Change {
 		if latestRelease.SHA == commit.SHA {
 			break
 		}
+		if IsSkipped(commit) {
+			continue
+		}
 		change.Major = change.Major || commit.Change.Major
 		change.Minor = change.Minor || commit.Change.Minor
 		change.Patch = change.Patch || commit.Change.Patch
@@ -56,20 +59,122 @@ func applyChange(rawVersion string, rawChange *Change, allowInitialDevelopmentVe
 		}
 		return newVersion.String()
 	}
-	preRelVer := strings.Split(preRel, ".")
-	if len(preRelVer) > 1 {
-		idx, err := strconv.ParseInt(preRelVer[1], 10, 32)
+	// a prerelease belongs to a version of a certain level, a bigger change
+	// starts the prerelease of the next version of that level
+	id, counter := splitPrerelease(preRel)
+	core, _ := version.SetPrerelease("")
+	switch {
+	case change.Major && (core.Minor() != 0 || core.Patch() != 0):
+		core, counter = core.IncMajor(), 0
+	case !change.Major && change.Minor && core.Patch() != 0:
+		core, counter = core.IncMinor(), 0
+	}
+	newVersion, _ = core.SetPrerelease(fmt.Sprintf("%s.%d", id, counter+1))
+	return newVersion.String()
+}
+
+// splitPrerelease splits a prerelease into its identifier and counter,
+// e.g. beta.3 into beta and 3. A missing or invalid counter is 0.
+func splitPrerelease(preRel string) (string, int64) {
+	parts := strings.Split(preRel, ".")
+	if len(parts) < 2 {
+		return parts[0], 0
+	}
+	counter, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return parts[0], 0
+	}
+	return parts[0], counter
+}
+
+// nextPrerelease moves the counter of a prerelease version behind the
+// counters of all existing releases of the same version and identifier. A
+// prerelease of a version that was already released is refused.
+func nextPrerelease(rawVersion string, allReleases []*Release) (string, error) {
+	version := semver.MustParse(rawVersion)
+	if version.Prerelease() == "" {
+		return rawVersion, nil
+	}
+	id, counter := splitPrerelease(version.Prerelease())
+	core, _ := version.SetPrerelease("")
+	for _, r := range allReleases {
+		existing, err := semver.NewVersion(r.Version)
 		if err != nil {
-			idx = 0
+			continue
+		}
+		existingCore, _ := existing.SetPrerelease("")
+		if !existingCore.Equal(&core) {
+			continue
+		}
+		if existing.Prerelease() == "" {
+			return "", fmt.Errorf("version %s was already released, a prerelease %s is not possible", existing.String(), rawVersion)
+		}
+		if existingID, existingCounter := splitPrerelease(existing.Prerelease()); existingID == id && existingCounter >= counter {
+			counter = existingCounter + 1
 		}
-		preRel = fmt.Sprintf("%s.%d", preRelVer[0], idx+1)
-	} else {
-		preRel += ".1"
 	}
-	newVersion, _ = version.SetPrerelease(preRel)
-	return newVersion.String()
+	newVersion, _ := core.SetPrerelease(fmt.Sprintf("%s.%d", id, counter))
+	return newVersion.String(), nil
 }
 
-func GetNewVersion(conf *config.Config, commits []*Commit, latestRelease *Release) string {
-	return applyChange(latestRelease.Version, calculateChange(commits, latestRelease), conf.AllowInitialDevelopmentVersions, conf.ForceBumpPatchVersion)
+// GetNewVersion calculates the version of the next release of branch with the
+// version scheme of conf. It is empty if the commits require no release.
+// Skipped commits are ignored and a "Release-As" footer pins the version.
+func GetNewVersion(conf *config.Config, branch *config.Branch, commits []*Commit, latestRelease *Release, allReleases []*Release) (string, error) {
+	scheme, err := NewVersionScheme(conf.VersionScheme, conf.CalVerFormat)
+	if err != nil {
+		return "", err
+	}
+	if commit := ReleaseAsCommit(commits, latestRelease); commit != nil {
+		version, err := pinnedVersion(scheme, commit, latestRelease)
+		if err != nil {
+			return "", err
+		}
+		if scheme.Name() == "semver" {
+			return version, checkBranchRange(branch, version)
+		}
+		return version, nil
+	}
+	return scheme.NextVersion(conf, branch, calculateChange(commits, latestRelease), latestRelease, allReleases)
+}
+
+// semverNextVersion calculates the next semantic version. The first
+// prerelease of a prerelease branch starts at the next stable version and the
+// versions of a maintenance branch have to stay within its range. The counter
+// of a prerelease follows all existing releases in allReleases.
+func semverNextVersion(conf *config.Config, branch *config.Branch, change *Change, latestRelease *Release, allReleases []*Release) (string, error) {
+	newVersion := applyChange(latestRelease.Version, change, conf.AllowInitialDevelopmentVersions, conf.ForceBumpPatchVersion)
+	if newVersion == "" {
+		return "", nil
+	}
+	if branch.Prerelease != "" && semver.MustParse(latestRelease.Version).Prerelease() == "" {
+		version, err := semver.MustParse(newVersion).SetPrerelease(branch.Prerelease + ".1")
+		if err != nil {
+			return "", err
+		}
+		newVersion = version.String()
+	}
+	if semver.MustParse(newVersion).Prerelease() != "" {
+		return nextPrerelease(newVersion, allReleases)
+	}
+	if err := checkBranchRange(branch, newVersion); err != nil {
+		return "", err
+	}
+	return newVersion, nil
+}
+
+// checkBranchRange returns an error if the semantic version is out of the
+// range of a maintenance branch.
+func checkBranchRange(branch *config.Branch, version string) error {
+	if branch.Range == "" {
+		return nil
+	}
+	constraint, err := semver.NewConstraint(branch.Range)
+	if err != nil {
+		return err
+	}
+	if !constraint.Check(semver.MustParse(version)) {
+		return fmt.Errorf("version %s is out of the range %s of branch %s", version, branch.Range, branch.Name)
+	}
+	return nil
 }
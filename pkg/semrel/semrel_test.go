@@ -6,6 +6,7 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCalculateChange(t *testing.T) {
@@ -22,12 +23,90 @@ func TestCalculateChange(t *testing.T) {
 	if change.Major || change.Minor || change.Patch {
 		t.Fail()
 	}
-	newVersion := GetNewVersion(&config.Config{}, commits, &Release{SHA: "b", Version: "1.0.0"})
-	if newVersion != "2.0.0" {
+	newVersion, err := GetNewVersion(&config.Config{}, &config.Branch{Name: "main"}, commits, &Release{SHA: "b", Version: "1.0.0"}, nil)
+	if err != nil || newVersion != "2.0.0" {
 		t.Fail()
 	}
 }
 
+func TestGetNewVersionForBranch(t *testing.T) {
+	minor := []*Commit{{SHA: "a", Change: &Change{Minor: true}}}
+	major := []*Commit{{SHA: "a", Change: &Change{Major: true}}}
+
+	testCases := []struct {
+		branch          *config.Branch
+		commits         []*Commit
+		latestVersion   string
+		expectedVersion string
+		expectedErr     string
+	}{
+		{&config.Branch{Name: "main"}, minor, "1.0.0", "1.1.0", ""},
+		{&config.Branch{Name: "next", Channel: "next"}, major, "1.0.0", "2.0.0", ""},
+		{&config.Branch{Name: "beta", Prerelease: "beta"}, minor, "1.0.0", "1.1.0-beta.1", ""},
+		{&config.Branch{Name: "beta", Prerelease: "beta"}, minor, "1.1.0-beta.1", "1.1.0-beta.2", ""},
+		{&config.Branch{Name: "1.x", Range: "1.x"}, minor, "1.2.0", "1.3.0", ""},
+		{&config.Branch{Name: "1.x", Range: "1.x"}, major, "1.2.0", "", "version 2.0.0 is out of the range 1.x of branch 1.x"},
+		{&config.Branch{Name: "1.2.x", Range: "1.2.x"}, minor, "1.2.0", "", "version 1.3.0 is out of the range 1.2.x of branch 1.2.x"},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Branch: %s, Version: %s", tc.branch.Name, tc.latestVersion), func(t *testing.T) {
+			actual, err := GetNewVersion(&config.Config{}, tc.branch, tc.commits, &Release{SHA: "b", Version: tc.latestVersion}, nil)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedVersion, actual)
+		})
+	}
+}
+
+func TestGetNewVersionOverrides(t *testing.T) {
+	testCases := []struct {
+		name            string
+		commits         []*Commit
+		branch          *config.Branch
+		expectedVersion string
+		expectedErr     string
+	}{
+		{"skip marker", []*Commit{{SHA: "a", Raw: []string{"feat: add search [skip release]"}, Change: &Change{Minor: true}}}, nil, "", ""},
+		{"skip footer", []*Commit{
+			{SHA: "a", Raw: []string{"feat: add search", "", "Release: skip"}, Change: &Change{Minor: true}},
+			{SHA: "c", Raw: []string{"fix: crash"}, Change: &Change{Patch: true}},
+		}, nil, "1.0.1", ""},
+		{"release-as", []*Commit{{SHA: "a", Raw: []string{"fix: crash", "", "Release-As: 2.0.0"}, Change: &Change{Patch: true}}}, nil, "2.0.0", ""},
+		{"latest release-as", []*Commit{
+			{SHA: "a", Raw: []string{"chore: release", "", "release-as: v1.5.0"}, Change: &Change{}},
+			{SHA: "c", Raw: []string{"chore: release", "", "Release-As: 2.0.0"}, Change: &Change{}},
+		}, nil, "1.5.0", ""},
+		{"skipped release-as", []*Commit{
+			{SHA: "a", Raw: []string{"chore: release [skip release]", "", "Release-As: 2.0.0"}, Change: &Change{}},
+			{SHA: "c", Raw: []string{"fix: crash"}, Change: &Change{Patch: true}},
+		}, nil, "1.0.1", ""},
+		{"not greater", []*Commit{{SHA: "a", Raw: []string{"fix: crash", "", "Release-As: 1.0.0"}, Change: &Change{Patch: true}}}, nil, "",
+			"version 1.0.0 of the Release-As footer of commit a is not greater than the latest release 1.0.0"},
+		{"invalid", []*Commit{{SHA: "a", Raw: []string{"fix: crash", "", "Release-As: next"}, Change: &Change{Patch: true}}}, nil, "",
+			"invalid Release-As version next of commit a: Invalid Semantic Version"},
+		{"out of range", []*Commit{{SHA: "a", Raw: []string{"fix: crash", "", "Release-As: 2.0.0"}, Change: &Change{Patch: true}}}, &config.Branch{Name: "1.x", Range: "1.x"}, "",
+			"version 2.0.0 is out of the range 1.x of branch 1.x"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			branch := tc.branch
+			if branch == nil {
+				branch = &config.Branch{Name: "main"}
+			}
+			actual, err := GetNewVersion(&config.Config{}, branch, tc.commits, &Release{SHA: "b", Version: "1.0.0"}, nil)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedVersion, actual)
+		})
+	}
+}
+
 func TestApplyChange(t *testing.T) {
 	NoChange := &Change{Major: false, Minor: false, Patch: false}
 	PatchChange := &Change{Major: false, Minor: false, Patch: true}
@@ -63,6 +142,12 @@ func TestApplyChange(t *testing.T) {
 		{"2.0.0-beta", MajorChange, "2.0.0-beta.1", false, false},
 		{"2.0.0-beta.2", MajorChange, "2.0.0-beta.3", false, false},
 		{"2.0.0-beta.1.1", MajorChange, "2.0.0-beta.2", false, false},
+		{"1.2.0-beta.3", MajorChange, "2.0.0-beta.1", false, false},
+		{"1.2.0-beta.3", MinorChange, "1.2.0-beta.4", false, false},
+		{"1.2.0-beta.3", PatchChange, "1.2.0-beta.4", false, false},
+		{"1.2.1-beta.2", MinorChange, "1.3.0-beta.1", false, false},
+		{"1.2.1-beta.2", PatchChange, "1.2.1-beta.3", false, false},
+		{"2.0.0-rc.1", MajorChange, "2.0.0-rc.2", false, false},
 	}
 
 	for _, tc := range testCases {
@@ -84,3 +169,42 @@ func TestApplyChange(t *testing.T) {
 		})
 	}
 }
+
+func TestNextPrerelease(t *testing.T) {
+	allReleases := []*Release{
+		{SHA: "a", Version: "1.0.0"},
+		{SHA: "b", Version: "1.1.0-beta.1"},
+		{SHA: "c", Version: "1.1.0-beta.4"},
+		{SHA: "d", Version: "1.1.0-alpha.7"},
+		{SHA: "e", Version: "2.0.0-beta.2"},
+	}
+	testCases := []struct {
+		version         string
+		expectedVersion string
+		expectedErr     string
+	}{
+		{"1.1.0-beta.2", "1.1.0-beta.5", ""},
+		{"1.1.0-alpha.1", "1.1.0-alpha.8", ""},
+		{"1.1.0-rc.1", "1.1.0-rc.1", ""},
+		{"2.0.0-beta.3", "2.0.0-beta.3", ""},
+		{"1.2.0", "1.2.0", ""},
+		{"1.0.0-beta.1", "", "version 1.0.0 was already released, a prerelease 1.0.0-beta.1 is not possible"},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Version: %s", tc.version), func(t *testing.T) {
+			actual, err := nextPrerelease(tc.version, allReleases)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedVersion, actual)
+		})
+	}
+
+	// a breaking change on a prerelease branch starts the next major version
+	commits := []*Commit{{SHA: "x", Change: &Change{Major: true, Minor: true, Patch: true}}}
+	newVersion, err := GetNewVersion(&config.Config{}, &config.Branch{Name: "beta", Prerelease: "beta"}, commits, &Release{SHA: "c", Version: "1.1.0-beta.4"}, allReleases)
+	require.NoError(t, err)
+	require.Equal(t, "2.0.0-beta.3", newVersion)
+}
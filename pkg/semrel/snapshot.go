@@ -0,0 +1,92 @@
+package semrel
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/duanqy/semantic-release/pkg/config"
+)
+
+// DefaultSnapshotTemplate creates versions like 1.4.0-snapshot.20261018.5+sha.abc1234.
+const DefaultSnapshotTemplate = "{{.Version}}-snapshot.{{.Date}}.{{.Commits}}+sha.{{.ShortSHA}}"
+
+// SnapshotData is passed to the snapshot version template.
+type SnapshotData struct {
+	// Version is the version of the next release.
+	Version string
+	// Commits is the number of commits since the latest release.
+	Commits int
+	// Date is the build date formatted as YYYYMMDD.
+	Date string
+	// SHA is the commit that is built, ShortSHA its first seven characters.
+	SHA      string
+	ShortSHA string
+}
+
+// GetSnapshotVersion returns the version of an unreleased build of sha. It is
+// based on the version that the next release would get, without relevant
+// changes the patch version is incremented.
+func GetSnapshotVersion(conf *config.Config, branch *config.Branch, commits []*Commit, latestRelease *Release, allReleases []*Release, sha string, date time.Time) (string, error) {
+	snapshotConf := *conf
+	snapshotConf.ForceBumpPatchVersion = true
+	version, err := GetNewVersion(&snapshotConf, branch, commits, latestRelease, allReleases)
+	if err != nil {
+		return "", err
+	}
+
+	rawTemplate := conf.SnapshotTemplate
+	if rawTemplate == "" {
+		rawTemplate = DefaultSnapshotTemplate
+	}
+	tmpl, err := template.New("snapshot").Option("missingkey=error").Parse(rawTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid snapshot template: %w", err)
+	}
+	shortSHA := sha
+	if len(shortSHA) > 7 {
+		shortSHA = shortSHA[:7]
+	}
+	sb := &strings.Builder{}
+	err = tmpl.Execute(sb, &SnapshotData{
+		Version:  version,
+		Commits:  countSHAs(commits),
+		Date:     date.UTC().Format("20060102"),
+		SHA:      sha,
+		ShortSHA: shortSHA,
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid snapshot template: %w", err)
+	}
+	if err := validateSnapshot(conf, sb.String()); err != nil {
+		return "", fmt.Errorf("invalid snapshot version %s: %w", sb.String(), err)
+	}
+	return sb.String(), nil
+}
+
+// validateSnapshot checks that version is valid in the configured scheme,
+// semantic versions have to be strict to be usable as package versions.
+func validateSnapshot(conf *config.Config, version string) error {
+	scheme, err := NewVersionScheme(conf.VersionScheme, conf.CalVerFormat)
+	if err != nil {
+		return err
+	}
+	if _, ok := scheme.(*semverScheme); ok {
+		_, err = semver.StrictNewVersion(version)
+		return err
+	}
+	_, err = scheme.Parse(version)
+	return err
+}
+
+// countSHAs returns the number of distinct commits, the entries of a split
+// squash commit share its SHA.
+func countSHAs(commits []*Commit) int {
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		seen[c.SHA] = true
+	}
+	return len(seen)
+}
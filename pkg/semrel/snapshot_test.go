@@ -0,0 +1,42 @@
+package semrel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duanqy/semantic-release/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSnapshotVersion(t *testing.T) {
+	require := require.New(t)
+	date := time.Date(2026, 10, 18, 23, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	branch := &config.Branch{Name: "main"}
+	latest := &Release{SHA: "a", Version: "1.3.2"}
+	features := []*Commit{
+		{SHA: "c", Change: &Change{Minor: true}},
+		{SHA: "b", Change: &Change{Patch: true}},
+	}
+
+	version, err := GetSnapshotVersion(&config.Config{}, branch, features, latest, nil, "abc1234567890", date)
+	require.NoError(err)
+	require.Equal("1.4.0-snapshot.20261018.2+sha.abc1234", version)
+
+	// the patch version is incremented without relevant changes
+	chores := []*Commit{{SHA: "b", Change: &Change{}}}
+	version, err = GetSnapshotVersion(&config.Config{SnapshotTemplate: "{{.Version}}-dev.{{.Commits}}+{{.SHA}}"}, branch, chores, latest, nil, "abc1234", date)
+	require.NoError(err)
+	require.Equal("1.3.3-dev.1+abc1234", version)
+
+	// the entries of a split squash commit count once
+	squashed := append(features, &Commit{SHA: "b", Change: &Change{}})
+	version, err = GetSnapshotVersion(&config.Config{}, branch, squashed, latest, nil, "abc1234567890", date)
+	require.NoError(err)
+	require.Equal("1.4.0-snapshot.20261018.2+sha.abc1234", version)
+
+	_, err = GetSnapshotVersion(&config.Config{SnapshotTemplate: "{{.Version}}-{{.Missing}}"}, branch, features, latest, nil, "abc1234", date)
+	require.Error(err)
+
+	_, err = GetSnapshotVersion(&config.Config{SnapshotTemplate: "snapshot-{{.Version}}"}, branch, features, latest, nil, "abc1234", date)
+	require.EqualError(err, "invalid snapshot version snapshot-1.4.0: Invalid characters in version")
+}
@@ -1,8 +1,22 @@
 package semrel
 
+import "time"
+
+// Signature identifies the author or the committer of a commit.
+type Signature struct {
+	Name  string
+	Email string
+	Date  time.Time
+}
+
 type RawCommit struct {
 	SHA         string
 	RawMessage  string
+	Author      *Signature
+	Committer   *Signature
+	Parents     []string
+	PullRequest int
+	Files       []string
 	Annotations map[string]string
 }
 
@@ -20,6 +34,10 @@ type Commit struct {
 	Scope       string
 	Message     string
 	Change      *Change
+	Author      *Signature
+	Committer   *Signature
+	Parents     []string
+	PullRequest int
 	Annotations map[string]string
 }
 
@@ -0,0 +1,111 @@
+package semrel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// VersionGroup is the name of the capturing group that marks the version part of
+// a tag in the expression passed to a provider's GetReleases.
+const VersionGroup = "version"
+
+// TagPrefixRegex returns an expression matching the tags that start with prefix.
+// The remainder of the tag is captured as version and has to start with match.
+func TagPrefixRegex(prefix, match string) string {
+	return fmt.Sprintf("^%s(?P<%s>%s.*)$", regexp.QuoteMeta(prefix), VersionGroup, match)
+}
+
+// DefaultTagFormat is the format of the release tags if none is configured.
+const DefaultTagFormat = "v{{.Version}}"
+
+// TagFormatData is passed to the tag format template.
+type TagFormatData struct {
+	Version string
+}
+
+// TagFormat creates the tag names of releases from a template like
+// "api/v{{.Version}}" and matches the tags created by it.
+type TagFormat struct {
+	prefix string
+	suffix string
+}
+
+// tagVersionMarker replaces the version while the template is analyzed.
+const tagVersionMarker = "\x00"
+
+// NewTagFormat parses a tag format template, the version has to be used
+// exactly once.
+func NewTagFormat(format string) (*TagFormat, error) {
+	tmpl, err := template.New("tag").Option("missingkey=error").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag format %s: %w", format, err)
+	}
+	sb := &strings.Builder{}
+	if err := tmpl.Execute(sb, &TagFormatData{Version: tagVersionMarker}); err != nil {
+		return nil, fmt.Errorf("invalid tag format %s: %w", format, err)
+	}
+	parts := strings.Split(sb.String(), tagVersionMarker)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid tag format %s: the version has to be used once", format)
+	}
+	return &TagFormat{prefix: parts[0], suffix: parts[1]}, nil
+}
+
+// Tag returns the tag name of version.
+func (f *TagFormat) Tag(version string) string {
+	return f.prefix + version + f.suffix
+}
+
+// Regex returns an expression matching the tags of the format. The version
+// is captured as version group and has to start with match.
+func (f *TagFormat) Regex(match string) string {
+	return fmt.Sprintf("^%s(?P<%s>%s.*)%s$", regexp.QuoteMeta(f.prefix), VersionGroup, match, regexp.QuoteMeta(f.suffix))
+}
+
+// ParseTagVersion parses the version of a tag matched by re and returns it in
+// the canonical form of scheme. If re contains a version group only the
+// captured part of the tag is parsed.
+func ParseTagVersion(scheme VersionScheme, re *regexp.Regexp, tag string) (string, error) {
+	if idx := re.SubexpIndex(VersionGroup); idx > 0 {
+		m := re.FindStringSubmatch(tag)
+		if m == nil {
+			return "", fmt.Errorf("tag %s does not match %s", tag, re)
+		}
+		tag = m[idx]
+	}
+	return scheme.Parse(tag)
+}
+
+// FilterCommitsByPath returns the commits that touch at least one file below
+// path, ignoring files that belong to one of the excluded paths.
+func FilterCommitsByPath(commits []*RawCommit, path string, exclude []string) []*RawCommit {
+	filtered := make([]*RawCommit, 0, len(commits))
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			if isBelowPath(file, path) && !isBelowAnyPath(file, exclude) {
+				filtered = append(filtered, commit)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func isBelowPath(file, path string) bool {
+	path = strings.Trim(path, "/")
+	if path == "" || path == "." {
+		return true
+	}
+	return file == path || strings.HasPrefix(file, path+"/")
+}
+
+func isBelowAnyPath(file string, paths []string) bool {
+	for _, path := range paths {
+		if isBelowPath(file, path) {
+			return true
+		}
+	}
+	return false
+}
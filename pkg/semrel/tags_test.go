@@ -0,0 +1,90 @@
+package semrel
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagVersion(t *testing.T) {
+	testCases := []struct {
+		re      string
+		tag     string
+		version string
+	}{
+		{"", "v1.2.3", "1.2.3"},
+		{"^v", "v2.0.0-beta.1", "2.0.0-beta.1"},
+		{TagPrefixRegex("api/v", ""), "api/v1.2.3", "1.2.3"},
+		{TagPrefixRegex("api/v", "2"), "api/v2.0.0", "2.0.0"},
+		{TagPrefixRegex("web@", ""), "web@0.1.0", "0.1.0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			version, err := ParseTagVersion(&semverScheme{}, regexp.MustCompile(tc.re), tc.tag)
+			require.NoError(t, err)
+			require.Equal(t, tc.version, version)
+		})
+	}
+
+	re := regexp.MustCompile(TagPrefixRegex("api/v", ""))
+	require.False(t, re.MatchString("v1.2.3"))
+	require.False(t, re.MatchString("web/api/v1.2.3"))
+	_, err := ParseTagVersion(&semverScheme{}, re, "web/v1.0.0")
+	require.Error(t, err)
+}
+
+func TestTagFormat(t *testing.T) {
+	testCases := []struct {
+		format  string
+		version string
+		tag     string
+	}{
+		{DefaultTagFormat, "1.2.3", "v1.2.3"},
+		{"api/v{{.Version}}", "2.0.0-beta.1", "api/v2.0.0-beta.1"},
+		{"release-{{.Version}}", "1.0.0", "release-1.0.0"},
+		{"{{.Version}}-final", "1.0.0", "1.0.0-final"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			format, err := NewTagFormat(tc.format)
+			require.NoError(t, err)
+			require.Equal(t, tc.tag, format.Tag(tc.version))
+			version, err := ParseTagVersion(&semverScheme{}, regexp.MustCompile(format.Regex("")), tc.tag)
+			require.NoError(t, err)
+			require.Equal(t, tc.version, version)
+		})
+	}
+
+	format, err := NewTagFormat("release-{{.Version}}")
+	require.NoError(t, err)
+	re := regexp.MustCompile(format.Regex("2"))
+	require.True(t, re.MatchString("release-2.1.0"))
+	require.False(t, re.MatchString("release-1.0.0"))
+	require.False(t, re.MatchString("v2.0.0"))
+
+	for _, invalid := range []string{"v{{.Version", "{{.Name}}", "release", "{{.Version}}-{{.Version}}"} {
+		_, err = NewTagFormat(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestFilterCommitsByPath(t *testing.T) {
+	commits := []*RawCommit{
+		{SHA: "a", Files: []string{"api/main.go"}},
+		{SHA: "b", Files: []string{"web/index.js", "README.md"}},
+		{SHA: "c", Files: []string{"api/v2/main.go"}},
+		{SHA: "d", Files: []string{"apiserver/main.go"}},
+		{SHA: "e"},
+	}
+	shas := func(commits []*RawCommit) []string {
+		res := make([]string, len(commits))
+		for i, c := range commits {
+			res[i] = c.SHA
+		}
+		return res
+	}
+	require.Equal(t, []string{"a", "c"}, shas(FilterCommitsByPath(commits, "api", nil)))
+	require.Equal(t, []string{"a"}, shas(FilterCommitsByPath(commits, "api/", []string{"api/v2"})))
+	require.Equal(t, []string{"b", "d"}, shas(FilterCommitsByPath(commits, "", []string{"api", "web/"})), "root package")
+}
@@ -59,11 +59,23 @@ func (g *DefaultChangelogGenerator) Version() string {
 
 func (g *DefaultChangelogGenerator) Generate(commits []*semrel.Commit, latestRelease *semrel.Release, newVersion string) string {
 	ret := fmt.Sprintf("## %s (%s)\n\n", newVersion, time.Now().UTC().Format("2006-01-02"))
+	if c := semrel.ReleaseAsCommit(commits, latestRelease); c != nil {
+		ret += fmt.Sprintf("_Version set by Release-As in %s_\n\n", trimSHA(c.SHA))
+	}
 	clTypes := NewChangelogTypes()
 	for _, commit := range commits {
 		if latestRelease.SHA == commit.SHA {
 			break
 		}
+		// a reverted commit and its revert cancel each other out
+		if commit.Annotations["revert_pair"] != "" {
+			continue
+		}
+		// skipped commits did not affect the version
+		if semrel.IsSkipped(commit) {
+			clTypes.AppendContent("%%skip%%", formatCommit(commit))
+			continue
+		}
 		if commit.Change != nil && commit.Change.Major {
 			bc := fmt.Sprintf("%s```\n%s\n```\n", formatCommit(commit), strings.Join(commit.Raw[1:], "\n"))
 			clTypes.AppendContent("%%bc%%", bc)
@@ -63,3 +63,31 @@ func TestEmojiGenerator(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestGeneratorSkipsRevertPairs(t *testing.T) {
+	commits := []*semrel.Commit{
+		{SHA: "bbbb", Type: "revert", Message: "feat(app): new login", Annotations: map[string]string{"revert_pair": "aaaa"}},
+		{SHA: "cccc", Type: "fix", Message: "kept fix"},
+		{SHA: "aaaa", Type: "feat", Scope: "app", Message: "new login", Annotations: map[string]string{"revert_pair": "bbbb"}},
+	}
+	changelog := (&DefaultChangelogGenerator{}).Generate(commits, &semrel.Release{}, "1.0.1")
+	if !strings.Contains(changelog, "* kept fix (cccc)") ||
+		strings.Contains(changelog, "new login") {
+		t.Fail()
+	}
+}
+
+func TestGeneratorReleaseOverrides(t *testing.T) {
+	commits := []*semrel.Commit{
+		{SHA: "aaaa", Type: "chore", Message: "release", Raw: []string{"chore: release", "", "Release-As: 2.0.0"}},
+		{SHA: "bbbb", Type: "feat", Message: "hidden search [skip release]", Raw: []string{"feat: hidden search [skip release]"}, Change: &semrel.Change{Minor: true}},
+		{SHA: "cccc", Type: "fix", Message: "crash", Raw: []string{"fix: crash"}},
+	}
+	changelog := (&DefaultChangelogGenerator{}).Generate(commits, &semrel.Release{}, "2.0.0")
+	if !strings.Contains(changelog, "_Version set by Release-As in aaaa_") ||
+		!strings.Contains(changelog, "#### Skipped\n\n* hidden search [skip release] (bbbb)") ||
+		strings.Contains(changelog, "#### Feature") ||
+		!strings.Contains(changelog, "* crash (cccc)") {
+		t.Fail()
+	}
+}
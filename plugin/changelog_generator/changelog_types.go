@@ -90,4 +90,9 @@ var defaultTypes = ChangelogTypes{
 		Text:  "CI",
 		Emoji: "🔁",
 	},
+	{
+		Type:  "%%skip%%",
+		Text:  "Skipped",
+		Emoji: "⏭",
+	},
 }
@@ -1,6 +1,8 @@
 package commit_analyzer
 
 import (
+	"fmt"
+	"path"
 	"regexp"
 	"strings"
 
@@ -11,16 +13,82 @@ import (
 
 func init() {
 	plugin.RegisterCommitAnalyzer(&DefaultCommitAnalyzer{})
+	plugin.RegisterCommitAnalyzer(&GitmojiCommitAnalyzer{})
 }
 
 var CAVERSION = "dev"
 var commitPattern = regexp.MustCompile(`^(\w*)(?:\((.*)\))?(!)?: (.*)$`)
-var breakingPattern = regexp.MustCompile("BREAKING CHANGES?")
 
-type DefaultCommitAnalyzer struct{}
+var releaseRulePattern = regexp.MustCompile(`^([^():!\s]+)(?:\(([^()]*)\))?(!)?:\s*(\w+)$`)
+
+// releaseRule assigns a release type to the commits of a type and scope, both
+// may be glob patterns. Breaking rules only match breaking changes and the
+// other rules only match commits without breaking changes.
+type releaseRule struct {
+	Type     string
+	Scope    string
+	Breaking bool
+	Release  string
+}
+
+func (r *releaseRule) match(commitType, scope string, breaking bool) bool {
+	if r.Breaking != breaking {
+		return false
+	}
+	if ok, _ := path.Match(r.Type, commitType); !ok {
+		return false
+	}
+	if r.Scope == "" {
+		return true
+	}
+	ok, _ := path.Match(r.Scope, scope)
+	return ok
+}
+
+// parseReleaseRules parses a comma or newline separated list of rules like
+// "perf:patch,docs(api):patch,feat(experimental/*):none,refactor!:minor".
+func parseReleaseRules(raw string) ([]*releaseRule, error) {
+	rules := make([]*releaseRule, 0)
+	for _, rawRule := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' }) {
+		rawRule = strings.TrimSpace(rawRule)
+		if rawRule == "" {
+			continue
+		}
+		found := releaseRulePattern.FindStringSubmatch(rawRule)
+		if found == nil {
+			return nil, fmt.Errorf("invalid release rule %s", rawRule)
+		}
+		rule := &releaseRule{Type: strings.ToLower(found[1]), Scope: found[2], Breaking: found[3] != "", Release: strings.ToLower(found[4])}
+		switch rule.Release {
+		case "major", "minor", "patch", "none":
+		default:
+			return nil, fmt.Errorf("invalid release rule %s: unknown release type %s", rawRule, found[4])
+		}
+		for _, pattern := range []string{rule.Type, rule.Scope} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid release rule %s: %w", rawRule, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+type DefaultCommitAnalyzer struct {
+	releaseRules []*releaseRule
+	splitBody    bool
+	mergeCommits string
+}
 
 func (da *DefaultCommitAnalyzer) Init(m map[string]string) error {
-	return nil
+	rules, err := parseReleaseRules(m["release_rules"])
+	if err != nil {
+		return err
+	}
+	da.releaseRules = rules
+	da.splitBody = m["split_body"] == "true"
+	da.mergeCommits, err = parseMergeCommitsMode(m["merge_commits"])
+	return err
 }
 
 func (da *DefaultCommitAnalyzer) Name() string {
@@ -31,10 +99,35 @@ func (da *DefaultCommitAnalyzer) Version() string {
 	return CAVERSION
 }
 
-func (da *DefaultCommitAnalyzer) analyzeSingleCommit(rawCommit *semrel.RawCommit) *semrel.Commit {
-	c := &semrel.Commit{Change: &semrel.Change{}}
+// newCommit returns the commit of rawCommit without a change. The annotations
+// of the provider are extended by the body and the footers of the message.
+func newCommit(rawCommit *semrel.RawCommit) (*semrel.Commit, []*footer) {
+	c := &semrel.Commit{
+		Change:      &semrel.Change{},
+		Author:      rawCommit.Author,
+		Committer:   rawCommit.Committer,
+		Parents:     rawCommit.Parents,
+		PullRequest: rawCommit.PullRequest,
+	}
 	c.SHA = rawCommit.SHA
 	c.Raw = strings.Split(rawCommit.RawMessage, "\n")
+
+	c.Annotations = make(map[string]string)
+	for k, v := range rawCommit.Annotations {
+		c.Annotations[k] = v
+	}
+	body, footers := parseBody(c.Raw[1:])
+	if body != "" {
+		c.Annotations["body"] = body
+	}
+	for k, v := range footerAnnotations(footers) {
+		c.Annotations[k] = v
+	}
+	return c, footers
+}
+
+func (da *DefaultCommitAnalyzer) analyzeSingleCommit(rawCommit *semrel.RawCommit) *semrel.Commit {
+	c, footers := newCommit(rawCommit)
 	found := commitPattern.FindAllStringSubmatch(c.Raw[0], -1)
 	if len(found) < 1 {
 		return c
@@ -44,7 +137,10 @@ func (da *DefaultCommitAnalyzer) analyzeSingleCommit(rawCommit *semrel.RawCommit
 	breakingChange := found[0][3]
 	c.Message = found[0][4]
 
-	isMajorChange := breakingPattern.MatchString(rawCommit.RawMessage)
+	isMajorChange := false
+	for _, f := range footers {
+		isMajorChange = isMajorChange || f.isBreaking()
+	}
 	isMinorChange := c.Type == "feat"
 	isPatchChange := c.Type == "fix"
 
@@ -54,6 +150,16 @@ func (da *DefaultCommitAnalyzer) analyzeSingleCommit(rawCommit *semrel.RawCommit
 		isPatchChange = false
 	}
 
+	// the first matching release rule overrides the default release type
+	for _, rule := range da.releaseRules {
+		if rule.match(c.Type, c.Scope, isMajorChange) {
+			isMajorChange = rule.Release == "major"
+			isMinorChange = rule.Release == "minor"
+			isPatchChange = rule.Release == "patch"
+			break
+		}
+	}
+
 	c.Change = &semrel.Change{
 		Major: isMajorChange,
 		Minor: isMinorChange,
@@ -63,9 +169,16 @@ func (da *DefaultCommitAnalyzer) analyzeSingleCommit(rawCommit *semrel.RawCommit
 }
 
 func (da *DefaultCommitAnalyzer) Analyze(rawCommits []*semrel.RawCommit) []*semrel.Commit {
-	ret := make([]*semrel.Commit, len(rawCommits))
-	for i, c := range rawCommits {
-		ret[i] = da.analyzeSingleCommit(c)
+	ret := make([]*semrel.Commit, 0, len(rawCommits))
+	for _, c := range filterMergeCommits(da.mergeCommits, rawCommits) {
+		if !da.splitBody {
+			ret = append(ret, da.analyzeSingleCommit(c))
+			continue
+		}
+		for _, entry := range splitBody(c, da.releaseRules) {
+			ret = append(ret, da.analyzeSingleCommit(entry))
+		}
 	}
+	dropReverts(ret)
 	return ret
 }
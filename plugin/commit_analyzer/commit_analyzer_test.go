@@ -3,6 +3,7 @@ package commit_analyzer
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/duanqy/semantic-release/pkg/semrel"
 	"github.com/stretchr/testify/require"
@@ -97,3 +98,255 @@ func TestDefaultAnalyzer(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultAnalyzerKeepsMetadata(t *testing.T) {
+	author := &semrel.Signature{Name: "author", Email: "author@example.com", Date: time.Date(2020, 4, 19, 12, 0, 0, 0, time.UTC)}
+	raw := createRawCommit("a", "feat: new feature")
+	raw.Author = author
+	raw.Committer = author
+	raw.Parents = []string{"b", "c"}
+	raw.PullRequest = 42
+
+	commit := (&DefaultCommitAnalyzer{}).analyzeSingleCommit(raw)
+	require.Equal(t, author, commit.Author)
+	require.Equal(t, author, commit.Committer)
+	require.Equal(t, []string{"b", "c"}, commit.Parents)
+	require.Equal(t, 42, commit.PullRequest)
+}
+
+func TestReleaseRules(t *testing.T) {
+	analyzer := &DefaultCommitAnalyzer{}
+	err := analyzer.Init(map[string]string{
+		"release_rules": "perf:patch, docs(api):patch,chore(deps):patch\nfeat(experimental/*):none,refactor(core)!:minor",
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		RawCommit *semrel.RawCommit
+		Type      string
+		Scope     string
+		Change    *semrel.Change
+	}{
+		{createRawCommit("a", "perf: faster parser"), "perf", "", &semrel.Change{Patch: true}},
+		{createRawCommit("b", "docs(api): describe endpoints"), "docs", "api", &semrel.Change{Patch: true}},
+		{createRawCommit("c", "docs(web): describe pages"), "docs", "web", &semrel.Change{}},
+		{createRawCommit("d", "chore(deps): bump go-github"), "chore", "deps", &semrel.Change{Patch: true}},
+		{createRawCommit("e", "feat(experimental/ui): new dashboard"), "feat", "experimental/ui", &semrel.Change{}},
+		{createRawCommit("f", "feat(ui): new dashboard"), "feat", "ui", &semrel.Change{Minor: true}},
+		{createRawCommit("g", "refactor(core)!: new plugin api"), "refactor", "core", &semrel.Change{Minor: true}},
+		{createRawCommit("h", "refactor(core): simplify"), "refactor", "core", &semrel.Change{}},
+		// rules without ! do not match breaking changes
+		{createRawCommit("i", "perf!: drop cache"), "perf", "", &semrel.Change{Major: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.RawCommit.RawMessage, func(t *testing.T) {
+			commit := analyzer.analyzeSingleCommit(tc.RawCommit)
+			require.True(t, compareCommit(commit, tc.Type, tc.Scope, tc.Change), "%+v", commit.Change)
+		})
+	}
+
+	for _, rules := range []string{"perf", "perf:huge", "perf(:patch", "[:patch"} {
+		require.Error(t, (&DefaultCommitAnalyzer{}).Init(map[string]string{"release_rules": rules}), rules)
+	}
+}
+
+func TestDefaultAnalyzerFooters(t *testing.T) {
+	raw := createRawCommit("a", `feat(api)!: switch to the v2 endpoints
+
+The old endpoints are removed.
+Closes: no footer as it is not at the start of a paragraph
+
+BREAKING CHANGE: the v1 endpoints are gone,
+use the v2 endpoints instead
+Refs: #12
+Closes #13
+Reviewed-by: Z
+Co-authored-by: A <a@example.com>
+Co-authored-by: B <b@example.com>`)
+	raw.Annotations = map[string]string{"author_login": "octocat"}
+
+	commit := (&DefaultCommitAnalyzer{}).analyzeSingleCommit(raw)
+	require.True(t, commit.Change.Major)
+	require.Equal(t, map[string]string{
+		"author_login":    "octocat",
+		"body":            "The old endpoints are removed.\nCloses: no footer as it is not at the start of a paragraph",
+		"breaking_change": "the v1 endpoints are gone,\nuse the v2 endpoints instead",
+		"refs":            "#12",
+		"closes":          "#13",
+		"reviewed-by":     "Z",
+		"co-authored-by":  "A <a@example.com>\nB <b@example.com>",
+	}, commit.Annotations)
+
+	// only the trailing paragraphs are footers
+	commit = (&DefaultCommitAnalyzer{}).analyzeSingleCommit(createRawCommit("c", `fix: retry uploads
+
+Uploads fail on slow connections.
+
+Note: the retries are not configurable yet
+
+Context: the timeout of the client is used.
+
+Refs: #14
+Reviewed-by: Z`))
+	require.False(t, commit.Change.Major)
+	require.Equal(t, map[string]string{
+		"body":        "Uploads fail on slow connections.\n\nNote: the retries are not configurable yet\n\nContext: the timeout of the client is used.",
+		"refs":        "#14",
+		"reviewed-by": "Z",
+	}, commit.Annotations)
+	commit = (&DefaultCommitAnalyzer{}).analyzeSingleCommit(createRawCommit("d", "fix: retry uploads\n\nNote: the retries are not configurable yet\n\nThey will be."))
+	require.Equal(t, map[string]string{"body": "Note: the retries are not configurable yet\n\nThey will be."}, commit.Annotations)
+
+	// breaking changes are only detected in the footers
+	testCases := []struct {
+		message  string
+		breaking bool
+	}{
+		{"fix: typo\n\nno BREAKING CHANGES here", false},
+		{"fix: typo\n\nthis is a BREAKING CHANGE: or not", false},
+		{"fix: typo\n\nBREAKING-CHANGE: renamed option", true},
+		{"fix: typo\n\nsome context\n\nBREAKING CHANGES: renamed option", true},
+		{"fix: typo\nBREAKING CHANGE: renamed option", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			commit := (&DefaultCommitAnalyzer{}).analyzeSingleCommit(createRawCommit("b", tc.message))
+			require.Equal(t, tc.breaking, commit.Change.Major)
+			_, ok := commit.Annotations["breaking_change"]
+			require.Equal(t, tc.breaking, ok)
+		})
+	}
+}
+
+func TestAnalyzeReverts(t *testing.T) {
+	shas := []string{
+		"1111111111111111111111111111111111111111",
+		"2222222222222222222222222222222222222222",
+		"3333333333333333333333333333333333333333",
+		"4444444444444444444444444444444444444444",
+		"5555555555555555555555555555555555555555",
+		"6666666666666666666666666666666666666666",
+	}
+	// newest commit first
+	rawCommits := []*semrel.RawCommit{
+		createRawCommit(shas[5], "revert: feat: released feature\n\nThis reverts commit 9999999999999999999999999999999999999999."),
+		createRawCommit(shas[4], "Revert \"Revert \\\"feat: search\\\"\"\n\nThis reverts commit "+shas[3]+"."),
+		createRawCommit(shas[3], "Revert \"feat: search\"\n\nThis reverts commit "+shas[2][:7]+"."),
+		createRawCommit(shas[2], "feat: search"),
+		createRawCommit(shas[1], "revert: feat(x): experiment\n\nThis reverts commit "+shas[0]+"."),
+		createRawCommit(shas[0], "feat(x): experiment"),
+	}
+	commits := (&DefaultCommitAnalyzer{}).Analyze(rawCommits)
+
+	// the experiment and its revert cancel each other out
+	require.Equal(t, &semrel.Change{}, commits[5].Change)
+	require.Equal(t, &semrel.Change{}, commits[4].Change)
+	require.Equal(t, shas[1], commits[5].Annotations["revert_pair"])
+	require.Equal(t, shas[0], commits[4].Annotations["revert_pair"])
+	require.Equal(t, shas[0], commits[4].Annotations["reverts"])
+
+	// the search is restored by reverting its revert
+	require.True(t, commits[3].Change.Minor)
+	require.Empty(t, commits[3].Annotations["revert_pair"])
+	require.Equal(t, shas[3], commits[1].Annotations["revert_pair"])
+	require.Equal(t, shas[4], commits[2].Annotations["revert_pair"])
+
+	// reverts of commits outside of the range are kept
+	require.Equal(t, "9999999999999999999999999999999999999999", commits[0].Annotations["reverts"])
+	require.Empty(t, commits[0].Annotations["revert_pair"])
+	require.Equal(t, "revert", commits[0].Type)
+}
+
+func TestAnalyzeSplitBody(t *testing.T) {
+	analyzer := &DefaultCommitAnalyzer{}
+	require.NoError(t, analyzer.Init(map[string]string{"split_body": "true"}))
+
+	squashed := createRawCommit("a", `Search page (#12)
+
+* feat(search): add search page
+
+* fix: typo in title
+  more details
+
+  BREAKING CHANGE: the title changed
+
+Co-authored-by: A <a@example.com>`)
+	squashed.PullRequest = 12
+	commits := analyzer.Analyze([]*semrel.RawCommit{
+		squashed,
+		createRawCommit("b", "fix: single commit\n\n- not an entry"),
+	})
+	require.Len(t, commits, 3)
+	require.True(t, compareCommit(commits[0], "feat", "search", &semrel.Change{Minor: true}))
+	require.Equal(t, "add search page", commits[0].Message)
+	require.True(t, compareCommit(commits[1], "fix", "", &semrel.Change{Major: true, Patch: true}))
+	require.Equal(t, "the title changed", commits[1].Annotations["breaking_change"])
+	require.Equal(t, "A <a@example.com>", commits[1].Annotations["co-authored-by"])
+	for _, c := range commits[:2] {
+		require.Equal(t, "a", c.SHA)
+		require.Equal(t, 12, c.PullRequest)
+	}
+	require.True(t, compareCommit(commits[2], "fix", "", &semrel.Change{Patch: true}))
+
+	// without the option the body is not split
+	commits = (&DefaultCommitAnalyzer{}).Analyze([]*semrel.RawCommit{squashed})
+	require.Len(t, commits, 1)
+
+	// prose bullets are not entries, the title is kept
+	commits = analyzer.Analyze([]*semrel.RawCommit{createRawCommit("c", "feat: add search (#12)\n\n- Note: see docs")})
+	require.Len(t, commits, 1)
+	require.True(t, compareCommit(commits[0], "feat", "", &semrel.Change{Minor: true}))
+	require.Equal(t, "add search (#12)", commits[0].Message)
+
+	// the types of release rules are entries
+	require.NoError(t, analyzer.Init(map[string]string{"split_body": "true", "release_rules": "deps:patch"}))
+	commits = analyzer.Analyze([]*semrel.RawCommit{createRawCommit("d", "Update (#13)\n\n- deps: bump x\n- Note: see docs")})
+	require.Len(t, commits, 1)
+	require.True(t, compareCommit(commits[0], "deps", "", &semrel.Change{Patch: true}))
+	require.Equal(t, []string{"deps: bump x", "- Note: see docs"}, commits[0].Raw)
+}
+
+func TestAnalyzeMergeCommits(t *testing.T) {
+	// main: a <- b <- m <- n, branch: a <- c <- d, m merges d and n merges x,
+	// which is older than the range
+	commit := func(sha, message string, parents ...string) *semrel.RawCommit {
+		c := createRawCommit(sha, message)
+		c.Parents = parents
+		return c
+	}
+	rawCommits := []*semrel.RawCommit{
+		commit("n", "feat: merge old branch", "m", "x"),
+		commit("m", "Merge pull request #1 from feature\n\nfeat: merged", "b", "d"),
+		commit("d", "fix: branch fix", "c"),
+		commit("c", "feat: branch feature", "a"),
+		commit("b", "chore: direct commit", "a"),
+		commit("a", "chore: base", "z"),
+	}
+	shas := func(commits []*semrel.Commit) []string {
+		res := make([]string, len(commits))
+		for i, c := range commits {
+			res[i] = c.SHA
+		}
+		return res
+	}
+
+	testCases := []struct {
+		mode string
+		shas []string
+	}{
+		{"", []string{"n", "m", "d", "c", "b", "a"}},
+		{"include", []string{"n", "m", "d", "c", "b", "a"}},
+		{"ignore", []string{"d", "c", "b", "a"}},
+		// the direct commits are kept, only the merge commits are replaced
+		{"second_parent", []string{"n", "d", "c", "b", "a"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			analyzer := &DefaultCommitAnalyzer{}
+			require.NoError(t, analyzer.Init(map[string]string{"merge_commits": tc.mode}))
+			require.Equal(t, tc.shas, shas(analyzer.Analyze(rawCommits)))
+		})
+	}
+
+	require.EqualError(t, (&DefaultCommitAnalyzer{}).Init(map[string]string{"merge_commits": "squash"}), "invalid merge_commits mode: squash")
+}
@@ -0,0 +1,103 @@
+package commit_analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// footerPattern matches the first line of a footer, a token followed by ": "
+// or " #". Tokens use "-" instead of spaces, except for BREAKING CHANGE.
+var footerPattern = regexp.MustCompile(`^(BREAKING[ -]CHANGES?|[\w-]+)(: | #)(.*)$`)
+
+// breakingChangeAnnotation is the annotation of the breaking change description.
+const breakingChangeAnnotation = "breaking_change"
+
+type footer struct {
+	token string
+	value string
+}
+
+// isBreaking reports whether the footer describes a breaking change.
+func (f *footer) isBreaking() bool {
+	return f.token == breakingChangeAnnotation
+}
+
+// parseFooterLine returns the footer started by line or nil.
+func parseFooterLine(line string) *footer {
+	found := footerPattern.FindStringSubmatch(line)
+	if found == nil {
+		return nil
+	}
+	token := strings.ToLower(found[1])
+	if strings.HasPrefix(token, "breaking") {
+		token = breakingChangeAnnotation
+	}
+	value := found[3]
+	if found[2] == " #" {
+		value = "#" + value
+	}
+	return &footer{token: token, value: value}
+}
+
+// footerStart returns the index of the line that starts the footers. Like
+// git trailers, the footers are the last paragraph if it begins with a footer
+// line, so a paragraph like "Note: ..." within the body is not taken as a
+// footer. As it is no prose, a BREAKING CHANGE footer always starts the
+// footers.
+func footerStart(lines []string) int {
+	paragraphs := make([]int, 0)
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" && (i == 0 || strings.TrimSpace(lines[i-1]) == "") {
+			paragraphs = append(paragraphs, i)
+		}
+	}
+	start := len(lines)
+	if len(paragraphs) > 0 && parseFooterLine(lines[paragraphs[len(paragraphs)-1]]) != nil {
+		start = paragraphs[len(paragraphs)-1]
+	}
+	for _, p := range paragraphs {
+		if p >= start {
+			break
+		}
+		if f := parseFooterLine(lines[p]); f != nil && f.isBreaking() {
+			return p
+		}
+	}
+	return start
+}
+
+// parseBody splits the lines following the header of a commit message into
+// the body and the footers. Within the footers, lines without a token
+// continue the value of the previous footer.
+func parseBody(lines []string) (string, []*footer) {
+	footers := make([]*footer, 0)
+	bodyEnd := footerStart(lines)
+	for _, line := range lines[bodyEnd:] {
+		if f := parseFooterLine(line); f != nil {
+			footers = append(footers, f)
+			continue
+		}
+		if len(footers) > 0 {
+			last := footers[len(footers)-1]
+			last.value += "\n" + line
+		}
+	}
+	for _, f := range footers {
+		f.value = strings.TrimSpace(f.value)
+	}
+	return strings.TrimSpace(strings.Join(lines[:bodyEnd], "\n")), footers
+}
+
+// footerAnnotations returns the footer values by token, the values of
+// repeated tokens are separated by newlines.
+func footerAnnotations(footers []*footer) map[string]string {
+	annotations := make(map[string]string)
+	for _, f := range footers {
+		if prev, ok := annotations[f.token]; ok {
+			annotations[f.token] = prev + "\n" + f.value
+			continue
+		}
+		annotations[f.token] = f.value
+	}
+	return annotations
+}
@@ -0,0 +1,170 @@
+package commit_analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+// gitmoji maps a shortcode and its emoji to a commit type and a release.
+type gitmoji struct {
+	Code    string
+	Emoji   string
+	Type    string
+	Release string
+}
+
+// defaultGitmojis is based on the semver field of https://gitmoji.dev. The
+// major release is reserved for breaking changes.
+var defaultGitmojis = []gitmoji{
+	{":boom:", "💥", "feat", "major"},
+	{":sparkles:", "✨", "feat", "minor"},
+	{":bug:", "🐛", "fix", "patch"},
+	{":ambulance:", "🚑", "fix", "patch"},
+	{":lock:", "🔒", "fix", "patch"},
+	{":pencil2:", "✏", "fix", "patch"},
+	{":adhesive_bandage:", "🩹", "fix", "patch"},
+	{":zap:", "⚡", "perf", "patch"},
+	{":lipstick:", "💄", "style", "patch"},
+	{":globe_with_meridians:", "🌐", "feat", "patch"},
+	{":rewind:", "⏪", "revert", "patch"},
+	{":arrow_up:", "⬆", "chore", "patch"},
+	{":arrow_down:", "⬇", "chore", "patch"},
+	{":pushpin:", "📌", "chore", "patch"},
+	{":package:", "📦", "build", "patch"},
+	{":wrench:", "🔧", "chore", "patch"},
+	{":memo:", "📝", "docs", "none"},
+	{":art:", "🎨", "style", "none"},
+	{":recycle:", "♻", "refactor", "none"},
+	{":fire:", "🔥", "refactor", "none"},
+	{":white_check_mark:", "✅", "test", "none"},
+	{":construction_worker:", "👷", "ci", "none"},
+	{":green_heart:", "💚", "ci", "none"},
+	{":rocket:", "🚀", "chore", "none"},
+	{":bookmark:", "🔖", "chore", "none"},
+	{":tada:", "🎉", "chore", "none"},
+}
+
+var (
+	gitmojiOverridePattern = regexp.MustCompile(`^(\S+)\s*=\s*(\w+):(\w+)$`)
+	shortcodePattern       = regexp.MustCompile(`^:[\w+-]+:`)
+	gitmojiScopePattern    = regexp.MustCompile(`^\(([^()]*)\):?\s*(.*)$`)
+)
+
+// normalizeEmoji removes the variation selector that some emoji are written with.
+func normalizeEmoji(s string) string {
+	return strings.ReplaceAll(s, "\ufe0f", "")
+}
+
+// parseGitmojis returns the gitmojis by shortcode and emoji. The overrides are
+// a comma or newline separated list like ":lipstick:=feat:minor,🚸=fix:patch",
+// overriding a shortcode or an emoji also changes its counterpart.
+func parseGitmojis(overrides string) (map[string]*gitmoji, error) {
+	gitmojis := make(map[string]*gitmoji)
+	for _, g := range defaultGitmojis {
+		g := g
+		gitmojis[g.Code] = &g
+		gitmojis[g.Emoji] = &g
+	}
+	for _, rawOverride := range strings.FieldsFunc(overrides, func(r rune) bool { return r == ',' || r == '\n' }) {
+		rawOverride = strings.TrimSpace(rawOverride)
+		if rawOverride == "" {
+			continue
+		}
+		found := gitmojiOverridePattern.FindStringSubmatch(rawOverride)
+		if found == nil {
+			return nil, fmt.Errorf("invalid gitmoji %s", rawOverride)
+		}
+		release := strings.ToLower(found[3])
+		switch release {
+		case "major", "minor", "patch", "none":
+		default:
+			return nil, fmt.Errorf("invalid gitmoji %s: unknown release type %s", rawOverride, found[3])
+		}
+		key := normalizeEmoji(found[1])
+		if g, ok := gitmojis[key]; ok {
+			g.Type, g.Release = strings.ToLower(found[2]), release
+			continue
+		}
+		gitmojis[key] = &gitmoji{Type: strings.ToLower(found[2]), Release: release}
+	}
+	return gitmojis, nil
+}
+
+// GitmojiCommitAnalyzer analyzes commits starting with a gitmoji shortcode or
+// emoji, e.g. ":sparkles: add search" or "💥 drop node 12".
+type GitmojiCommitAnalyzer struct {
+	gitmojis map[string]*gitmoji
+}
+
+func (ga *GitmojiCommitAnalyzer) Init(m map[string]string) error {
+	gitmojis, err := parseGitmojis(m["gitmojis"])
+	if err != nil {
+		return err
+	}
+	ga.gitmojis = gitmojis
+	return nil
+}
+
+func (ga *GitmojiCommitAnalyzer) Name() string {
+	return "gitmoji"
+}
+
+func (ga *GitmojiCommitAnalyzer) Version() string {
+	return CAVERSION
+}
+
+// findGitmoji returns the gitmoji that header starts with and the remainder
+// of the header.
+func (ga *GitmojiCommitAnalyzer) findGitmoji(header string) (*gitmoji, string) {
+	header = normalizeEmoji(header)
+	if code := shortcodePattern.FindString(header); code != "" {
+		return ga.gitmojis[code], header[len(code):]
+	}
+	var found *gitmoji
+	length := 0
+	for key, g := range ga.gitmojis {
+		if !strings.HasPrefix(key, ":") && len(key) > length && strings.HasPrefix(header, key) {
+			found, length = g, len(key)
+		}
+	}
+	return found, header[length:]
+}
+
+func (ga *GitmojiCommitAnalyzer) analyzeSingleCommit(rawCommit *semrel.RawCommit) *semrel.Commit {
+	if ga.gitmojis == nil {
+		ga.gitmojis, _ = parseGitmojis("")
+	}
+	c, footers := newCommit(rawCommit)
+	g, rest := ga.findGitmoji(c.Raw[0])
+	if g == nil {
+		return c
+	}
+	c.Type = g.Type
+	c.Message = strings.TrimSpace(rest)
+	if found := gitmojiScopePattern.FindStringSubmatch(c.Message); found != nil {
+		c.Scope, c.Message = found[1], found[2]
+	}
+
+	isBreaking := g.Release == "major"
+	for _, f := range footers {
+		isBreaking = isBreaking || f.isBreaking()
+	}
+	c.Change = &semrel.Change{
+		Major: isBreaking,
+		Minor: !isBreaking && g.Release == "minor",
+		Patch: !isBreaking && g.Release == "patch",
+	}
+	return c
+}
+
+func (ga *GitmojiCommitAnalyzer) Analyze(rawCommits []*semrel.RawCommit) []*semrel.Commit {
+	ret := make([]*semrel.Commit, len(rawCommits))
+	for i, c := range rawCommits {
+		ret[i] = ga.analyzeSingleCommit(c)
+	}
+	dropReverts(ret)
+	return ret
+}
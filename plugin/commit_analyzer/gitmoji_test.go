@@ -0,0 +1,61 @@
+package commit_analyzer
+
+import (
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitmojiAnalyzer(t *testing.T) {
+	testCases := []struct {
+		RawCommit *semrel.RawCommit
+		Type      string
+		Scope     string
+		Message   string
+		Change    *semrel.Change
+	}{
+		{createRawCommit("a", ":sparkles: add search"), "feat", "", "add search", &semrel.Change{Minor: true}},
+		{createRawCommit("b", "✨ (search): add filters"), "feat", "search", "add filters", &semrel.Change{Minor: true}},
+		{createRawCommit("c", "💥 drop node 12"), "feat", "", "drop node 12", &semrel.Change{Major: true}},
+		{createRawCommit("d", ":bug: fix crash"), "fix", "", "fix crash", &semrel.Change{Patch: true}},
+		// the emoji is written with a variation selector
+		{createRawCommit("e", "⬆️ bump dependencies"), "chore", "", "bump dependencies", &semrel.Change{Patch: true}},
+		{createRawCommit("f", "♻️refactor parser"), "refactor", "", "refactor parser", &semrel.Change{}},
+		{createRawCommit("g", ":memo: update docs\n\nBREAKING CHANGE: the docs moved"), "docs", "", "update docs", &semrel.Change{Major: true}},
+		{createRawCommit("h", ":unknown: something"), "", "", "", &semrel.Change{}},
+		{createRawCommit("i", "feat: conventional commit"), "", "", "", &semrel.Change{}},
+	}
+
+	analyzer := &GitmojiCommitAnalyzer{}
+	require.NoError(t, analyzer.Init(map[string]string{}))
+	for _, tc := range testCases {
+		t.Run(tc.RawCommit.RawMessage, func(t *testing.T) {
+			commit := analyzer.analyzeSingleCommit(tc.RawCommit)
+			require.True(t, compareCommit(commit, tc.Type, tc.Scope, tc.Change), "%s %s %+v", commit.Type, commit.Scope, commit.Change)
+			require.Equal(t, tc.Message, commit.Message)
+		})
+	}
+}
+
+func TestGitmojiOverrides(t *testing.T) {
+	analyzer := &GitmojiCommitAnalyzer{}
+	require.NoError(t, analyzer.Init(map[string]string{
+		"gitmojis": "💄=feat:minor, :memo:=docs:patch\n:children_crossing:=fix:patch",
+	}))
+
+	commit := analyzer.analyzeSingleCommit(createRawCommit("a", ":lipstick: new theme"))
+	require.True(t, compareCommit(commit, "feat", "", &semrel.Change{Minor: true}))
+	commit = analyzer.analyzeSingleCommit(createRawCommit("b", "📝 describe options"))
+	require.True(t, compareCommit(commit, "docs", "", &semrel.Change{Patch: true}))
+	commit = analyzer.analyzeSingleCommit(createRawCommit("c", ":children_crossing: simpler login"))
+	require.True(t, compareCommit(commit, "fix", "", &semrel.Change{Patch: true}))
+
+	// the defaults are not changed by an override
+	commit = (&GitmojiCommitAnalyzer{}).analyzeSingleCommit(createRawCommit("d", "💄 new theme"))
+	require.True(t, compareCommit(commit, "style", "", &semrel.Change{Patch: true}))
+
+	for _, overrides := range []string{":memo:", ":memo:=docs", ":memo:=docs:huge"} {
+		require.Error(t, (&GitmojiCommitAnalyzer{}).Init(map[string]string{"gitmojis": overrides}), overrides)
+	}
+}
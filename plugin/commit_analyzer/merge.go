@@ -0,0 +1,86 @@
+package commit_analyzer
+
+import (
+	"fmt"
+
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+const (
+	mergeCommitsInclude      = "include"
+	mergeCommitsIgnore       = "ignore"
+	mergeCommitsSecondParent = "second_parent"
+)
+
+func parseMergeCommitsMode(mode string) (string, error) {
+	switch mode {
+	case "", mergeCommitsInclude:
+		return mergeCommitsInclude, nil
+	case mergeCommitsIgnore, mergeCommitsSecondParent:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid merge_commits mode: %s", mode)
+}
+
+func isMergeCommit(c *semrel.RawCommit) bool {
+	return len(c.Parents) > 1
+}
+
+// filterMergeCommits applies the merge commits mode. Merge commits are left
+// out by the ignore mode. The second_parent mode replaces a merge commit by the
+// commits that were merged through its second parent, the merge commit is only
+// kept if none of them is part of rawCommits. Commits made directly on the
+// branch are kept by both modes.
+func filterMergeCommits(mode string, rawCommits []*semrel.RawCommit) []*semrel.RawCommit {
+	if mode != mergeCommitsIgnore && mode != mergeCommitsSecondParent {
+		return rawCommits
+	}
+	var merged map[string][]string
+	if mode == mergeCommitsSecondParent {
+		merged = secondParentRanges(rawCommits)
+	}
+	filtered := make([]*semrel.RawCommit, 0, len(rawCommits))
+	for _, c := range rawCommits {
+		if isMergeCommit(c) && (mode == mergeCommitsIgnore || len(merged[c.SHA]) > 0) {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// secondParentRanges returns the SHAs of the commits that are reachable from
+// the second parent of a merge commit but not from its first parent by the
+// SHA of the merge commit. Only the given commits are considered.
+func secondParentRanges(rawCommits []*semrel.RawCommit) map[string][]string {
+	bySHA := make(map[string]*semrel.RawCommit, len(rawCommits))
+	for _, c := range rawCommits {
+		bySHA[c.SHA] = c
+	}
+	ancestors := func(sha string, exclude map[string]bool) map[string]bool {
+		seen := make(map[string]bool)
+		queue := []string{sha}
+		for len(queue) > 0 {
+			sha, queue = queue[0], queue[1:]
+			c, ok := bySHA[sha]
+			if !ok || seen[sha] || exclude[sha] {
+				continue
+			}
+			seen[sha] = true
+			queue = append(queue, c.Parents...)
+		}
+		return seen
+	}
+
+	merged := make(map[string][]string)
+	for _, c := range rawCommits {
+		if !isMergeCommit(c) {
+			continue
+		}
+		base := ancestors(c.Parents[0], nil)
+		for sha := range ancestors(c.Parents[1], base) {
+			merged[c.SHA] = append(merged[c.SHA], sha)
+		}
+	}
+	return merged
+}
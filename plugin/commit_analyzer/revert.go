@@ -0,0 +1,83 @@
+package commit_analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+var revertPattern = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-fA-F]{7,40})`)
+
+const (
+	// revertsAnnotation is the SHA of the commit that a revert commit reverts.
+	revertsAnnotation = "reverts"
+	// revertPairAnnotation is set on a revert commit and the commit it reverts
+	// if both are in the analyzed range, it is the SHA of the other commit.
+	revertPairAnnotation = "revert_pair"
+)
+
+// revertedSHA returns the SHA of the commit reverted by c or an empty string.
+func revertedSHA(c *semrel.Commit) string {
+	found := revertPattern.FindStringSubmatch(strings.Join(c.Raw, "\n"))
+	if found == nil {
+		return ""
+	}
+	return strings.ToLower(found[1])
+}
+
+// dropReverts removes the changes of the commits that are reverted in the
+// range and of the reverts themselves. A revert only counts if it is not
+// reverted itself, so reverting a revert restores the original commit.
+func dropReverts(commits []*semrel.Commit) {
+	findCommit := func(sha string) *semrel.Commit {
+		for _, c := range commits {
+			if strings.HasPrefix(c.SHA, sha) {
+				return c
+			}
+		}
+		return nil
+	}
+
+	revertedBy := make(map[*semrel.Commit][]*semrel.Commit)
+	targets := make(map[*semrel.Commit]*semrel.Commit)
+	for _, c := range commits {
+		sha := revertedSHA(c)
+		if sha == "" {
+			continue
+		}
+		c.Annotations[revertsAnnotation] = sha
+		if target := findCommit(sha); target != nil && target != c {
+			targets[c] = target
+			revertedBy[target] = append(revertedBy[target], c)
+		}
+	}
+
+	active := make(map[*semrel.Commit]bool)
+	var isActive func(c *semrel.Commit) bool
+	isActive = func(c *semrel.Commit) bool {
+		if res, ok := active[c]; ok {
+			return res
+		}
+		res := true
+		for _, revert := range revertedBy[c] {
+			if isActive(revert) {
+				res = false
+				break
+			}
+		}
+		active[c] = res
+		return res
+	}
+
+	for _, revert := range commits {
+		target, ok := targets[revert]
+		if !ok || !isActive(revert) || isActive(target) {
+			continue
+		}
+		revert.Change = &semrel.Change{}
+		target.Change = &semrel.Change{}
+		revert.Annotations[revertPairAnnotation] = target.SHA
+		target.Annotations[revertPairAnnotation] = revert.SHA
+	}
+}
@@ -0,0 +1,75 @@
+package commit_analyzer
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+// bodyEntryPattern matches a list item in the body of a squashed or merged
+// commit, e.g. "* feat: add search".
+var bodyEntryPattern = regexp.MustCompile(`^([*-] +)(.*)$`)
+
+// entryTypes are the commit types of the list items that splitBody splits on,
+// other items like "- Note: see docs" are prose.
+var entryTypes = []string{"feat", "fix", "perf", "refactor", "revert", "build", "chore", "ci", "docs", "style", "test"}
+
+// isEntryType reports whether commitType is a known type or matches the type
+// of a release rule.
+func isEntryType(commitType string, rules []*releaseRule) bool {
+	commitType = strings.ToLower(commitType)
+	for _, t := range entryTypes {
+		if t == commitType {
+			return true
+		}
+	}
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.Type, commitType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitBody splits a commit whose body lists several conventional commits
+// into one raw commit per entry, all of them share the SHA of the commit.
+// Only list items of known types are entries. The lines following an entry
+// belong to its message. Commits without such entries are returned unchanged.
+func splitBody(rawCommit *semrel.RawCommit, rules []*releaseRule) []*semrel.RawCommit {
+	lines := strings.Split(rawCommit.RawMessage, "\n")
+	entries := make([]*semrel.RawCommit, 0)
+	var message []string
+	indent := ""
+	flush := func() {
+		if message == nil {
+			return
+		}
+		entry := *rawCommit
+		entry.RawMessage = strings.TrimRight(strings.Join(message, "\n"), "\n")
+		entries = append(entries, &entry)
+	}
+	for _, line := range lines[1:] {
+		found := bodyEntryPattern.FindStringSubmatch(line)
+		if found != nil && isConventionalEntry(found[2], rules) {
+			flush()
+			message = []string{found[2]}
+			indent = strings.Repeat(" ", len(found[1]))
+			continue
+		}
+		if message != nil {
+			message = append(message, strings.TrimPrefix(line, indent))
+		}
+	}
+	flush()
+	if len(entries) == 0 {
+		return []*semrel.RawCommit{rawCommit}
+	}
+	return entries
+}
+
+func isConventionalEntry(line string, rules []*releaseRule) bool {
+	found := commitPattern.FindStringSubmatch(line)
+	return found != nil && isEntryType(found[1], rules)
+}
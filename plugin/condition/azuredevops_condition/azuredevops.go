@@ -0,0 +1,59 @@
+package azuredevops_condition
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+)
+
+func init() {
+	plugin.RegisterCICondition(&AzurePipelines{})
+}
+
+var CIVERSION = "dev"
+
+type AzurePipelines struct {
+}
+
+func (ap *AzurePipelines) Name() string {
+	return "azuredevops"
+}
+
+func (ap *AzurePipelines) Version() string {
+	return CIVERSION
+}
+
+func (ap *AzurePipelines) GetCurrentBranch() string {
+	return strings.TrimPrefix(os.Getenv("BUILD_SOURCEBRANCH"), "refs/heads/")
+}
+
+func (ap *AzurePipelines) GetCurrentSHA() string {
+	return os.Getenv("BUILD_SOURCEVERSION")
+}
+
+func (ap *AzurePipelines) IsBranchRef() bool {
+	if val := os.Getenv("BUILD_SOURCEBRANCH"); val != "" {
+		return strings.HasPrefix(val, "refs/heads/")
+	}
+	return false
+}
+
+func (ap *AzurePipelines) IsPullRequest() bool {
+	return os.Getenv("BUILD_REASON") == "PullRequest"
+}
+
+func (ap *AzurePipelines) RunCondition(config map[string]string) error {
+	branches := plugin.ReleaseBranches(config)
+	if ap.IsPullRequest() {
+		return fmt.Errorf("This test run was triggered by a pull request and therefore a new version won't be published.")
+	}
+	if !ap.IsBranchRef() {
+		return fmt.Errorf("This test run is not running on a branch build.")
+	}
+	if branch := ap.GetCurrentBranch(); !plugin.MatchBranch(branches, branch) {
+		return fmt.Errorf("This test run was triggered on the branch %s, while semantic-release is configured to only publish from %s.", branch, strings.Join(branches, ", "))
+	}
+	return nil
+}
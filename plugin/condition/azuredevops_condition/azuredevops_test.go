@@ -0,0 +1,26 @@
+package azuredevops_condition
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAzurePipelinesValid(t *testing.T) {
+	ap := AzurePipelines{}
+	os.Setenv("BUILD_SOURCEBRANCH", "")
+	os.Setenv("BUILD_REASON", "")
+	err := ap.RunCondition(map[string]string{"defaultBranch": ""})
+	assert.EqualError(t, err, "This test run is not running on a branch build.")
+
+	os.Setenv("BUILD_SOURCEBRANCH", "refs/pull/12/merge")
+	os.Setenv("BUILD_REASON", "PullRequest")
+	err = ap.RunCondition(map[string]string{"defaultBranch": "main"})
+	assert.EqualError(t, err, "This test run was triggered by a pull request and therefore a new version won't be published.")
+
+	os.Setenv("BUILD_SOURCEBRANCH", "refs/heads/main")
+	os.Setenv("BUILD_REASON", "IndividualCI")
+	assert.Equal(t, "main", ap.GetCurrentBranch())
+	assert.NoError(t, ap.RunCondition(map[string]string{"defaultBranch": "main"}))
+}
@@ -0,0 +1,56 @@
+package bitbucket_condition
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+)
+
+func init() {
+	plugin.RegisterCICondition(&BitbucketPipelines{})
+}
+
+var CIVERSION = "dev"
+
+type BitbucketPipelines struct {
+}
+
+func (bp *BitbucketPipelines) Name() string {
+	return "bitbucket"
+}
+
+func (bp *BitbucketPipelines) Version() string {
+	return CIVERSION
+}
+
+func (bp *BitbucketPipelines) GetCurrentBranch() string {
+	return os.Getenv("BITBUCKET_BRANCH")
+}
+
+func (bp *BitbucketPipelines) GetCurrentSHA() string {
+	return os.Getenv("BITBUCKET_COMMIT")
+}
+
+func (bp *BitbucketPipelines) IsBranchRef() bool {
+	return bp.GetCurrentBranch() != ""
+}
+
+func (bp *BitbucketPipelines) IsPullRequest() bool {
+	return os.Getenv("BITBUCKET_PR_ID") != ""
+}
+
+func (bp *BitbucketPipelines) RunCondition(config map[string]string) error {
+	branches := plugin.ReleaseBranches(config)
+	if bp.IsPullRequest() {
+		return fmt.Errorf("This test run was triggered by a pull request and therefore a new version won't be published.")
+	}
+	if !bp.IsBranchRef() {
+		return fmt.Errorf("This test run is not running on a branch build.")
+	}
+	if branch := bp.GetCurrentBranch(); !plugin.MatchBranch(branches, branch) {
+		return fmt.Errorf("This test run was triggered on the branch %s, while semantic-release is configured to only publish from %s.", branch, strings.Join(branches, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package bitbucket_condition
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitbucketValid(t *testing.T) {
+	bp := BitbucketPipelines{}
+	os.Setenv("BITBUCKET_BRANCH", "")
+	os.Setenv("BITBUCKET_PR_ID", "")
+	err := bp.RunCondition(map[string]string{"defaultBranch": ""})
+	assert.EqualError(t, err, "This test run is not running on a branch build.")
+
+	os.Setenv("BITBUCKET_BRANCH", "feature")
+	os.Setenv("BITBUCKET_PR_ID", "12")
+	err = bp.RunCondition(map[string]string{"defaultBranch": "master"})
+	assert.EqualError(t, err, "This test run was triggered by a pull request and therefore a new version won't be published.")
+
+	os.Setenv("BITBUCKET_PR_ID", "")
+	err = bp.RunCondition(map[string]string{"defaultBranch": "master"})
+	assert.EqualError(t, err, "This test run was triggered on the branch feature, while semantic-release is configured to only publish from master.")
+
+	os.Setenv("BITBUCKET_BRANCH", "master")
+	assert.NoError(t, bp.RunCondition(map[string]string{"defaultBranch": "master"}))
+}
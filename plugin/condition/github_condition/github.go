@@ -41,12 +41,12 @@ func (gha *GitHubActions) IsBranchRef() bool {
 }
 
 func (gha *GitHubActions) RunCondition(config map[string]string) error {
-	defaultBranch := config["defaultBranch"]
+	branches := plugin.ReleaseBranches(config)
 	if !gha.IsBranchRef() {
 		return fmt.Errorf("This test run is not running on a branch build.")
 	}
-	if branch := gha.GetCurrentBranch(); defaultBranch != "*" && branch != defaultBranch {
-		return fmt.Errorf("This test run was triggered on the branch %s, while semantic-release is configured to only publish from %s.", branch, defaultBranch)
+	if branch := gha.GetCurrentBranch(); !plugin.MatchBranch(branches, branch) {
+		return fmt.Errorf("This test run was triggered on the branch %s, while semantic-release is configured to only publish from %s.", branch, strings.Join(branches, ", "))
 	}
 	return nil
 }
@@ -13,3 +13,13 @@ func TestGithubValid(t *testing.T) {
 	err := gha.RunCondition(map[string]string{"defaultBranch": ""})
 	assert.EqualError(t, err, "This test run is not running on a branch build.")
 }
+
+func TestGithubReleaseBranches(t *testing.T) {
+	gha := GitHubActions{}
+	os.Setenv("GITHUB_REF", "refs/heads/release/1.x")
+	defer os.Unsetenv("GITHUB_REF")
+	err := gha.RunCondition(map[string]string{"defaultBranch": "main", "releaseBranches": "main,release/*"})
+	assert.NoError(t, err)
+	err = gha.RunCondition(map[string]string{"defaultBranch": "main", "releaseBranches": "main,beta"})
+	assert.EqualError(t, err, "This test run was triggered on the branch release/1.x, while semantic-release is configured to only publish from main, beta.")
+}
@@ -3,6 +3,7 @@ package gitlab_condition
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/duanqy/semantic-release/pkg/plugin"
 )
@@ -37,12 +38,12 @@ func (gl *GitLab) IsBranchRef() bool {
 }
 
 func (gl *GitLab) RunCondition(config map[string]string) error {
-	defaultBranch := config["defaultBranch"]
+	branches := plugin.ReleaseBranches(config)
 	if !gl.IsBranchRef() {
 		return fmt.Errorf("This test run is not running on a branch build.")
 	}
-	if branch := gl.GetCurrentBranch(); defaultBranch != "*" && branch != defaultBranch {
-		return fmt.Errorf("This test run was triggered on the branch %s, while semantic-release is configured to only publish from %s.", branch, defaultBranch)
+	if branch := gl.GetCurrentBranch(); !plugin.MatchBranch(branches, branch) {
+		return fmt.Errorf("This test run was triggered on the branch %s, while semantic-release is configured to only publish from %s.", branch, strings.Join(branches, ", "))
 	}
 	return nil
 }
@@ -13,3 +13,13 @@ func TestGitlabValid(t *testing.T) {
 	err := gl.RunCondition(map[string]string{"defaultBranch": ""})
 	assert.EqualError(t, err, "This test run is not running on a branch build.")
 }
+
+func TestGitlabReleaseBranches(t *testing.T) {
+	gl := GitLab{}
+	os.Setenv("CI_COMMIT_BRANCH", "beta")
+	defer os.Unsetenv("CI_COMMIT_BRANCH")
+	err := gl.RunCondition(map[string]string{"defaultBranch": "main", "releaseBranches": "main,beta"})
+	assert.NoError(t, err)
+	err = gl.RunCondition(map[string]string{"defaultBranch": "main"})
+	assert.EqualError(t, err, "This test run was triggered on the branch beta, while semantic-release is configured to only publish from main.")
+}
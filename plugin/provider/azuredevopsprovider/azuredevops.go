@@ -0,0 +1,416 @@
+package azuredevopsprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+func init() {
+	plugin.RegisterProvider(&AzureDevOpsRepository{})
+}
+
+var PVERSION = "dev"
+
+const apiVersion = "6.0"
+
+// annotatedTagsAPIVersion is the version of the annotated tags API, it is only
+// available as preview.
+const annotatedTagsAPIVersion = "6.0-preview.1"
+
+type AzureDevOpsRepository struct {
+	project string
+	repo    string
+	token   string
+	baseURL *url.URL
+	client  *http.Client
+
+	commitFiles   bool
+	versionScheme semrel.VersionScheme
+}
+
+type azureRepo struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	DefaultBranch string `json:"defaultBranch"`
+	Project       struct {
+		Name       string `json:"name"`
+		Visibility string `json:"visibility"`
+	} `json:"project"`
+}
+
+type azureChangeList struct {
+	Changes []struct {
+		Item struct {
+			Path     string `json:"path"`
+			IsFolder bool   `json:"isFolder"`
+		} `json:"item"`
+	} `json:"changes"`
+}
+
+type azureGitUser struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+type azureCommit struct {
+	CommitID         string        `json:"commitId"`
+	Comment          string        `json:"comment"`
+	CommentTruncated bool          `json:"commentTruncated"`
+	Author           *azureGitUser `json:"author"`
+	Committer        *azureGitUser `json:"committer"`
+	Parents          []string      `json:"parents"`
+}
+
+func (u *azureGitUser) toSignature() *semrel.Signature {
+	if u == nil {
+		return nil
+	}
+	return &semrel.Signature{Name: u.Name, Email: u.Email, Date: u.Date}
+}
+
+type azureCommitList struct {
+	Count int            `json:"count"`
+	Value []*azureCommit `json:"value"`
+}
+
+type azureRef struct {
+	Name           string `json:"name"`
+	ObjectID       string `json:"objectId"`
+	PeeledObjectID string `json:"peeledObjectId"`
+}
+
+type azureRefList struct {
+	Value []*azureRef `json:"value"`
+}
+
+type azureGitObject struct {
+	ObjectID string `json:"objectId"`
+}
+
+type azureCreateAnnotatedTag struct {
+	Name         string          `json:"name"`
+	TaggedObject *azureGitObject `json:"taggedObject"`
+	Message      string          `json:"message"`
+}
+
+type azureRefUpdate struct {
+	Name        string `json:"name"`
+	OldObjectID string `json:"oldObjectId"`
+	NewObjectID string `json:"newObjectId"`
+}
+
+type azureRefUpdateResult struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Message string `json:"customMessage"`
+}
+
+type azureRefUpdateResultList struct {
+	Value []*azureRefUpdateResult `json:"value"`
+}
+
+type azureError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *azureError) Error() string {
+	return fmt.Sprintf("azure devops: %d %s", e.StatusCode, e.Message)
+}
+
+func (repo *AzureDevOpsRepository) Init(config map[string]string) error {
+	baseURL := config["azure_baseurl"]
+	if baseURL == "" {
+		baseURL = os.Getenv("SYSTEM_COLLECTIONURI")
+	}
+	if baseURL == "" {
+		return errors.New("azure_baseurl is required")
+	}
+	token := config["token"]
+	if token == "" {
+		token = os.Getenv("AZURE_DEVOPS_TOKEN")
+	}
+	if token == "" {
+		token = os.Getenv("SYSTEM_ACCESSTOKEN")
+	}
+	if token == "" {
+		return errors.New("azure devops token missing")
+	}
+	project := config["azure_project"]
+	if project == "" {
+		project = os.Getenv("SYSTEM_TEAMPROJECT")
+	}
+	if project == "" {
+		return errors.New("azure_project is required")
+	}
+	name := config["azure_repo"]
+	if name == "" {
+		name = os.Getenv("BUILD_REPOSITORY_NAME")
+	}
+	if name == "" {
+		return errors.New("azure_repo is required")
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return fmt.Errorf("invalid azure_baseurl: %w", err)
+	}
+	repo.baseURL = u
+	repo.project = project
+	repo.repo = name
+	repo.token = token
+	repo.client = http.DefaultClient
+	repo.commitFiles = config["commit_files"] == "true"
+	versionScheme, err := semrel.NewVersionScheme(config["version_scheme"], config["calver_format"])
+	if err != nil {
+		return err
+	}
+	repo.versionScheme = versionScheme
+	return nil
+}
+
+// do sends an authenticated request to the Azure DevOps REST API and decodes the JSON response into out.
+func (repo *AzureDevOpsRepository) do(method, path string, query url.Values, body, out interface{}) (*http.Response, error) {
+	u, err := repo.baseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	if query.Get("api-version") == "" {
+		query.Set("api-version", apiVersion)
+	}
+	u.RawQuery = query.Encode()
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	// personal access tokens and the pipeline access token are both accepted with an empty user name
+	req.SetBasicAuth("", repo.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := repo.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return resp, &azureError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+func (repo *AzureDevOpsRepository) repoPath(format string, a ...interface{}) string {
+	return fmt.Sprintf("%s/_apis/git/repositories/%s", url.PathEscape(repo.project), url.PathEscape(repo.repo)) + fmt.Sprintf(format, a...)
+}
+
+func (repo *AzureDevOpsRepository) GetInfo() (*plugin.RepositoryInfo, error) {
+	r := &azureRepo{}
+	if _, err := repo.do(http.MethodGet, repo.repoPath(""), nil, nil, r); err != nil {
+		return nil, err
+	}
+	return &plugin.RepositoryInfo{
+		Owner:         r.Project.Name,
+		Repo:          r.Name,
+		DefaultBranch: strings.TrimPrefix(r.DefaultBranch, "refs/heads/"),
+		Private:       r.Project.Visibility != "public",
+	}, nil
+}
+
+func (repo *AzureDevOpsRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	allCommits := make([]*semrel.RawCommit, 0)
+	const top = 100
+	for skip := 0; ; skip += top {
+		query := url.Values{}
+		query.Set("searchCriteria.itemVersion.version", toSha)
+		query.Set("searchCriteria.itemVersion.versionType", "commit")
+		query.Set("searchCriteria.$top", fmt.Sprint(top))
+		query.Set("searchCriteria.$skip", fmt.Sprint(skip))
+		commits := &azureCommitList{}
+		if _, err := repo.do(http.MethodGet, repo.repoPath("/commits"), query, nil, commits); err != nil {
+			return nil, err
+		}
+		for _, commit := range commits.Value {
+			if commit.CommitID == fromSha {
+				return allCommits, nil
+			}
+			message := commit.Comment
+			// the list endpoint cuts long commit messages
+			if commit.CommentTruncated {
+				full := &azureCommit{}
+				if _, err := repo.do(http.MethodGet, repo.repoPath("/commits/%s", commit.CommitID), nil, nil, full); err != nil {
+					return nil, err
+				}
+				message = full.Comment
+			}
+			rawCommit := &semrel.RawCommit{
+				SHA:        commit.CommitID,
+				RawMessage: message,
+				Author:     commit.Author.toSignature(),
+				Committer:  commit.Committer.toSignature(),
+				Parents:    commit.Parents,
+			}
+			if repo.commitFiles {
+				files, err := repo.getCommitFiles(commit.CommitID)
+				if err != nil {
+					return nil, err
+				}
+				rawCommit.Files = files
+			}
+			allCommits = append(allCommits, rawCommit)
+		}
+		if len(commits.Value) < top {
+			break
+		}
+	}
+	return allCommits, nil
+}
+
+func (repo *AzureDevOpsRepository) getCommitFiles(sha string) ([]string, error) {
+	files := make([]string, 0)
+	const top = 100
+	for skip := 0; ; skip += top {
+		query := url.Values{}
+		query.Set("top", fmt.Sprint(top))
+		query.Set("skip", fmt.Sprint(skip))
+		changes := &azureChangeList{}
+		if _, err := repo.do(http.MethodGet, repo.repoPath("/commits/%s/changes", sha), query, nil, changes); err != nil {
+			return nil, err
+		}
+		for _, change := range changes.Changes {
+			if change.Item.IsFolder {
+				continue
+			}
+			// item paths are absolute to the repository root
+			files = append(files, strings.TrimPrefix(change.Item.Path, "/"))
+		}
+		if len(changes.Changes) < top {
+			break
+		}
+	}
+	return files, nil
+}
+
+func (repo *AzureDevOpsRepository) GetReleases(rawRe string) ([]*semrel.Release, error) {
+	re := regexp.MustCompile(rawRe)
+	allReleases := make([]*semrel.Release, 0)
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("filter", "tags/")
+		query.Set("peelTags", "true")
+		if continuationToken != "" {
+			query.Set("continuationToken", continuationToken)
+		}
+		refs := &azureRefList{}
+		resp, err := repo.do(http.MethodGet, repo.repoPath("/refs"), query, nil, refs)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range refs.Value {
+			tag := strings.TrimPrefix(r.Name, "refs/tags/")
+			if rawRe != "" && !re.MatchString(tag) {
+				continue
+			}
+			version, err := semrel.ParseTagVersion(repo.versionScheme, re, tag)
+			if err != nil {
+				continue
+			}
+			// annotated tags are resolved to their commit by peelTags
+			sha := r.ObjectID
+			if r.PeeledObjectID != "" {
+				sha = r.PeeledObjectID
+			}
+			allReleases = append(allReleases, &semrel.Release{SHA: sha, Version: version})
+		}
+		continuationToken = resp.Header.Get("X-Ms-Continuationtoken")
+		if continuationToken == "" {
+			break
+		}
+	}
+	return allReleases, nil
+}
+
+func (repo *AzureDevOpsRepository) CreateRelease(release *plugin.CreateReleaseConfig) error {
+	tag := release.TagName()
+	// Azure Repos has no notion of releases, the changelog is stored as tag message
+	query := url.Values{"api-version": {annotatedTagsAPIVersion}}
+	_, err := repo.do(http.MethodPost, repo.repoPath("/annotatedtags"), query, &azureCreateAnnotatedTag{
+		Name:         tag,
+		TaggedObject: &azureGitObject{ObjectID: release.SHA},
+		Message:      release.Changelog,
+	}, nil)
+	return err
+}
+
+func (repo *AzureDevOpsRepository) DeleteRelease(release *plugin.CreateReleaseConfig) error {
+	ref := "refs/tags/" + release.TagName()
+	query := url.Values{}
+	// the filter matches prefixes, the exact ref is picked below
+	query.Set("filter", strings.TrimPrefix(ref, "refs/"))
+	refs := &azureRefList{}
+	if _, err := repo.do(http.MethodGet, repo.repoPath("/refs"), query, nil, refs); err != nil {
+		return err
+	}
+	for _, r := range refs.Value {
+		if r.Name != ref {
+			continue
+		}
+		// a ref is deleted by updating it to the zero object id
+		results := &azureRefUpdateResultList{}
+		_, err := repo.do(http.MethodPost, repo.repoPath("/refs"), nil, []*azureRefUpdate{{
+			Name:        ref,
+			OldObjectID: r.ObjectID,
+			NewObjectID: strings.Repeat("0", 40),
+		}}, results)
+		if err != nil {
+			return err
+		}
+		for _, res := range results.Value {
+			if !res.Success {
+				return fmt.Errorf("could not delete %s: %s", res.Name, res.Message)
+			}
+		}
+	}
+	return nil
+}
+
+func (repo *AzureDevOpsRepository) Name() string {
+	return "azuredevops"
+}
+
+func (repo *AzureDevOpsRepository) Version() string {
+	return PVERSION
+}
@@ -0,0 +1,287 @@
+package azuredevopsprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAzureDevOpsRepository(t *testing.T) {
+	require := require.New(t)
+
+	var repo *AzureDevOpsRepository
+	repo = &AzureDevOpsRepository{}
+	err := repo.Init(map[string]string{})
+	require.EqualError(err, "azure_baseurl is required")
+
+	repo = &AzureDevOpsRepository{}
+	err = repo.Init(map[string]string{
+		"azure_baseurl": "https://dev.azure.com/org",
+	})
+	require.EqualError(err, "azure devops token missing")
+
+	repo = &AzureDevOpsRepository{}
+	err = repo.Init(map[string]string{
+		"azure_baseurl": "https://dev.azure.com/org",
+		"token":         "token",
+		"azure_project": "project",
+	})
+	require.EqualError(err, "azure_repo is required")
+
+	repo = &AzureDevOpsRepository{}
+	err = repo.Init(map[string]string{
+		"azure_baseurl": "https://dev.azure.com/org",
+		"token":         "token",
+		"azure_project": "project",
+		"azure_repo":    "test-repo",
+	})
+	require.NoError(err)
+	require.Equal("https://dev.azure.com/org/", repo.baseURL.String())
+}
+
+func createAzureCommit(sha, message string) *azureCommit {
+	return &azureCommit{CommitID: sha, Comment: message}
+}
+
+func createAzureRef(name, sha string) *azureRef {
+	return &azureRef{Name: "refs/tags/" + name, ObjectID: sha}
+}
+
+func createAzureAnnotatedRef(name, sha, commitSha string) *azureRef {
+	return &azureRef{Name: "refs/tags/" + name, ObjectID: sha, PeeledObjectID: commitSha}
+}
+
+var (
+	AZURE_COMMITS = []*azureCommit{
+		createAzureCommit("abcd", "feat(app): new new feature"),
+		createAzureCommit("1111", "feat: to"),
+		createAzureCommit("abcd", "feat(app): new feature"),
+		{CommitID: "dcba", Comment: "Fix: bug", CommentTruncated: true},
+		createAzureCommit("cdba", "Initial commit"),
+		createAzureCommit("efcd", "chore: break\nBREAKING CHANGE: breaks everything"),
+		createAzureCommit("2222", "feat: from"),
+		createAzureCommit("beef", "fix: test"),
+	}
+	AZURE_TAGS = []*azureRef{
+		createAzureRef("test-tag", "deadbeef"),
+		createAzureRef("v1.0.0", "deadbeef"),
+		createAzureRef("v2.0.0", "deadbeef"),
+		createAzureRef("v2.1.0-beta", "deadbeef"),
+		createAzureRef("v3.0.0-beta.2", "deadbeef"),
+		createAzureRef("v3.0.0-beta.1", "deadbeef"),
+		createAzureRef("2020.04.19", "deadbeef"),
+		createAzureAnnotatedRef("v1.1.1", "12345678", "deadbeef"),
+	}
+)
+
+//nolint:errcheck
+func azureHandler(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "" || pass != "token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	const repoPath = "/org/project/_apis/git/repositories/test-repo"
+	version := apiVersion
+	if r.URL.Path == repoPath+"/annotatedtags" {
+		version = annotatedTagsAPIVersion
+	}
+	if r.URL.Query().Get("api-version") != version {
+		http.Error(w, "invalid api version", http.StatusBadRequest)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath {
+		fmt.Fprint(w, `{"id":"1","name":"test-repo","defaultBranch":"refs/heads/main","project":{"name":"project","visibility":"private"}}`)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath+"/commits" {
+		toSha := r.URL.Query().Get("searchCriteria.itemVersion.version")
+		skip := 0
+		for i, commit := range AZURE_COMMITS {
+			if commit.CommitID == toSha {
+				skip = i
+				break
+			}
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("searchCriteria.$skip"))
+		commits := AZURE_COMMITS[skip+offset:]
+		json.NewEncoder(w).Encode(azureCommitList{Count: len(commits), Value: commits})
+		return
+	}
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, repoPath+"/commits/") && strings.HasSuffix(r.URL.Path, "/changes") {
+		sha := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, repoPath+"/commits/"), "/changes")
+		fmt.Fprintf(w, `{"changes":[{"item":{"path":"/%s","isFolder":true}},{"item":{"path":"/%s/main.go"}}]}`, sha, sha)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath+"/commits/dcba" {
+		json.NewEncoder(w).Encode(createAzureCommit("dcba", "Fix: bug\n\nwith a long body"))
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath+"/refs" {
+		if r.URL.Query().Get("filter") != "tags/" || r.URL.Query().Get("peelTags") != "true" {
+			http.Error(w, "invalid filter", http.StatusBadRequest)
+			return
+		}
+		// serve the tags in two pages
+		refs := AZURE_TAGS[:4]
+		if r.URL.Query().Get("continuationToken") == "next" {
+			refs = AZURE_TAGS[4:]
+		} else {
+			w.Header().Set("x-ms-continuationtoken", "next")
+		}
+		json.NewEncoder(w).Encode(azureRefList{Value: refs})
+		return
+	}
+	if r.Method == "POST" && r.URL.Path == repoPath+"/annotatedtags" {
+		var data azureCreateAnnotatedTag
+		json.NewDecoder(r.Body).Decode(&data)
+		r.Body.Close()
+		if data.Name != "v2.0.0" || data.TaggedObject == nil || data.TaggedObject.ObjectID != "deadbeef" {
+			http.Error(w, "invalid tag", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "{}")
+		return
+	}
+	http.Error(w, "invalid route", http.StatusNotImplemented)
+}
+
+func getNewAzureTestRepo(t *testing.T) (*AzureDevOpsRepository, *httptest.Server) {
+	ts := httptest.NewServer(http.HandlerFunc(azureHandler))
+	repo := &AzureDevOpsRepository{}
+	err := repo.Init(map[string]string{
+		"azure_baseurl": ts.URL + "/org",
+		"azure_project": "project",
+		"azure_repo":    "test-repo",
+		"token":         "token",
+	})
+	require.NoError(t, err)
+	return repo, ts
+}
+
+func TestAzureDevOpsGetInfo(t *testing.T) {
+	repo, ts := getNewAzureTestRepo(t)
+	defer ts.Close()
+	repoInfo, err := repo.GetInfo()
+	require.NoError(t, err)
+	require.Equal(t, "main", repoInfo.DefaultBranch)
+	require.Equal(t, "project", repoInfo.Owner)
+	require.Equal(t, "test-repo", repoInfo.Repo)
+	require.True(t, repoInfo.Private)
+}
+
+func TestAzureDevOpsGetCommits(t *testing.T) {
+	repo, ts := getNewAzureTestRepo(t)
+	defer ts.Close()
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+
+	for i, c := range commits {
+		idxOff := i + 1
+		require.Equal(t, c.SHA, AZURE_COMMITS[idxOff].CommitID)
+	}
+	require.Equal(t, "Fix: bug\n\nwith a long body", commits[2].RawMessage)
+}
+
+func TestAzureDevOpsGetCommitsWithFiles(t *testing.T) {
+	repo, ts := getNewAzureTestRepo(t)
+	defer ts.Close()
+	repo.commitFiles = true
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+
+	for _, c := range commits {
+		require.Equal(t, []string{c.SHA + "/main.go"}, c.Files)
+	}
+}
+
+func TestAzureDevOpsGetReleases(t *testing.T) {
+	repo, ts := getNewAzureTestRepo(t)
+	defer ts.Close()
+
+	testCases := []struct {
+		vrange          string
+		re              string
+		expectedSHA     string
+		expectedVersion string
+	}{
+		{"", "", "deadbeef", "2020.4.19"},
+		{"", "^v[0-9]*", "deadbeef", "2.0.0"},
+		{"2-beta", "", "deadbeef", "2.1.0-beta"},
+		{"3-beta", "", "deadbeef", "3.0.0-beta.2"},
+		{"4-beta", "", "deadbeef", "4.0.0-beta"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("VersionRange: %s, RE: %s", tc.vrange, tc.re), func(t *testing.T) {
+			releases, err := repo.GetReleases(tc.re)
+			require.NoError(t, err)
+			release, err := semrel.GetLatestReleaseFromReleases(releases, tc.vrange)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSHA, release.SHA)
+			require.Equal(t, tc.expectedVersion, release.Version)
+		})
+	}
+}
+
+func TestAzureDevOpsCreateRelease(t *testing.T) {
+	repo, ts := getNewAzureTestRepo(t)
+	defer ts.Close()
+	err := repo.CreateRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+}
+
+func TestAzureDevOpsDeleteRelease(t *testing.T) {
+	require := require.New(t)
+	const repoPath = "/org/project/_apis/git/repositories/test-repo"
+	var updates []*azureRefUpdate
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == repoPath+"/refs" && r.URL.Query().Get("filter") == "tags/v2.0.0" {
+			json.NewEncoder(w).Encode(azureRefList{Value: []*azureRef{ //nolint:errcheck
+				{Name: "refs/tags/v2.0.0", ObjectID: "1234"},
+				{Name: "refs/tags/v2.0.0-beta", ObjectID: "5678"},
+			}})
+			return
+		}
+		if r.Method == "POST" && r.URL.Path == repoPath+"/refs" {
+			json.NewDecoder(r.Body).Decode(&updates) //nolint:errcheck
+			fmt.Fprint(w, `{"value":[{"name":"refs/tags/v2.0.0","success":true}]}`)
+			return
+		}
+		if r.Method == "GET" && r.URL.Path == repoPath+"/refs" && r.URL.Query().Get("filter") == "tags/v3.0.0" {
+			fmt.Fprint(w, `{"value":[]}`)
+			return
+		}
+		azureHandler(w, r)
+	}))
+	defer ts.Close()
+	repo := &AzureDevOpsRepository{}
+	err := repo.Init(map[string]string{
+		"azure_baseurl": ts.URL + "/org",
+		"azure_project": "project",
+		"azure_repo":    "test-repo",
+		"token":         "token",
+	})
+	require.NoError(err)
+
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0"}))
+	require.Len(updates, 1)
+	require.Equal("refs/tags/v2.0.0", updates[0].Name)
+	require.Equal("1234", updates[0].OldObjectID)
+	require.Equal(strings.Repeat("0", 40), updates[0].NewObjectID)
+
+	// tags that were never created are ignored
+	updates = nil
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "3.0.0"}))
+	require.Nil(updates)
+}
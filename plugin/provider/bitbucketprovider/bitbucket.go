@@ -0,0 +1,386 @@
+package bitbucketprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+func init() {
+	plugin.RegisterProvider(&BitbucketRepository{})
+}
+
+var PVERSION = "dev"
+
+type BitbucketRepository struct {
+	project  string
+	repo     string
+	username string
+	token    string
+	baseURL  *url.URL
+	client   *http.Client
+
+	commitFiles   bool
+	versionScheme semrel.VersionScheme
+}
+
+type bitbucketPage struct {
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+	Values        json.RawMessage `json:"values"`
+}
+
+type bitbucketRepo struct {
+	Slug    string `json:"slug"`
+	Public  bool   `json:"public"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+}
+
+type bitbucketBranch struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId"`
+}
+
+type bitbucketPath struct {
+	ToString string `json:"toString"`
+}
+
+type bitbucketChange struct {
+	Path    bitbucketPath  `json:"path"`
+	SrcPath *bitbucketPath `json:"srcPath"`
+}
+
+type bitbucketUser struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type bitbucketCommit struct {
+	ID                 string         `json:"id"`
+	Message            string         `json:"message"`
+	Author             *bitbucketUser `json:"author"`
+	AuthorTimestamp    int64          `json:"authorTimestamp"`
+	Committer          *bitbucketUser `json:"committer"`
+	CommitterTimestamp int64          `json:"committerTimestamp"`
+	Parents            []struct {
+		ID string `json:"id"`
+	} `json:"parents"`
+}
+
+// toSignature converts a Bitbucket user and its timestamp in milliseconds.
+func (u *bitbucketUser) toSignature(timestamp int64) *semrel.Signature {
+	if u == nil {
+		return nil
+	}
+	return &semrel.Signature{Name: u.Name, Email: u.EmailAddress, Date: time.Unix(0, timestamp*int64(time.Millisecond)).UTC()}
+}
+
+type bitbucketTag struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	Hash         string `json:"hash"`
+}
+
+type bitbucketCreateTag struct {
+	Name       string `json:"name"`
+	StartPoint string `json:"startPoint"`
+	Message    string `json:"message"`
+}
+
+type bitbucketError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *bitbucketError) Error() string {
+	return fmt.Sprintf("bitbucket: %d %s", e.StatusCode, e.Message)
+}
+
+func (repo *BitbucketRepository) Init(config map[string]string) error {
+	baseURL := config["bitbucket_baseurl"]
+	if baseURL == "" {
+		baseURL = os.Getenv("BITBUCKET_SERVER_URL")
+	}
+	if baseURL == "" {
+		return errors.New("bitbucket_baseurl is required")
+	}
+	token := config["token"]
+	if token == "" {
+		token = os.Getenv("BITBUCKET_TOKEN")
+	}
+	if token == "" {
+		return errors.New("bitbucket token missing")
+	}
+
+	slug := config["slug"]
+	if split := strings.SplitN(slug, "/", 2); len(split) == 2 {
+		repo.project = split[0]
+		repo.repo = split[1]
+	}
+	if project := config["bitbucket_project"]; project != "" {
+		repo.project = project
+	}
+	if name := config["bitbucket_repo"]; name != "" {
+		repo.repo = name
+	}
+	if repo.project == "" || repo.repo == "" {
+		return errors.New("invalid slug")
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/rest/api/1.0/")
+	if err != nil {
+		return fmt.Errorf("invalid bitbucket_baseurl: %w", err)
+	}
+	repo.baseURL = u
+	repo.username = config["bitbucket_username"]
+	repo.token = token
+	repo.client = http.DefaultClient
+	repo.commitFiles = config["commit_files"] == "true"
+	versionScheme, err := semrel.NewVersionScheme(config["version_scheme"], config["calver_format"])
+	if err != nil {
+		return err
+	}
+	repo.versionScheme = versionScheme
+	return nil
+}
+
+// do sends an authenticated request to the Bitbucket REST API and decodes the JSON response into out.
+func (repo *BitbucketRepository) do(method, path string, body, out interface{}) error {
+	u, err := repo.baseURL.Parse(path)
+	if err != nil {
+		return err
+	}
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	// personal access tokens are sent as bearer tokens, app passwords need basic auth
+	if repo.username != "" {
+		req.SetBasicAuth(repo.username, repo.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+repo.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := repo.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return &bitbucketError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// paginate requests all pages of a paged Bitbucket resource and calls fn with the raw values of each page.
+// Returning false from fn stops the pagination.
+func (repo *BitbucketRepository) paginate(path string, query url.Values, fn func(values json.RawMessage) (bool, error)) error {
+	start := 0
+	for {
+		query.Set("start", fmt.Sprint(start))
+		query.Set("limit", "100")
+		page := &bitbucketPage{}
+		if err := repo.do(http.MethodGet, path+"?"+query.Encode(), nil, page); err != nil {
+			return err
+		}
+		next, err := fn(page.Values)
+		if err != nil {
+			return err
+		}
+		if !next || page.IsLastPage {
+			return nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+func (repo *BitbucketRepository) repoPath(format string, a ...interface{}) string {
+	return fmt.Sprintf("projects/%s/repos/%s", url.PathEscape(repo.project), url.PathEscape(repo.repo)) + fmt.Sprintf(format, a...)
+}
+
+func (repo *BitbucketRepository) getDefaultBranch() (string, error) {
+	branch := &bitbucketBranch{}
+	err := repo.do(http.MethodGet, repo.repoPath("/default-branch"), nil, branch)
+	var bErr *bitbucketError
+	if errors.As(err, &bErr) && bErr.StatusCode == http.StatusNotFound {
+		// servers older than 7.5 only provide the deprecated endpoint
+		err = repo.do(http.MethodGet, repo.repoPath("/branches/default"), nil, branch)
+	}
+	if err != nil {
+		return "", err
+	}
+	if branch.DisplayID != "" {
+		return branch.DisplayID, nil
+	}
+	return strings.TrimPrefix(branch.ID, "refs/heads/"), nil
+}
+
+func (repo *BitbucketRepository) GetInfo() (*plugin.RepositoryInfo, error) {
+	r := &bitbucketRepo{}
+	if err := repo.do(http.MethodGet, repo.repoPath(""), nil, r); err != nil {
+		return nil, err
+	}
+	defaultBranch, err := repo.getDefaultBranch()
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.RepositoryInfo{
+		Owner:         r.Project.Key,
+		Repo:          r.Slug,
+		DefaultBranch: defaultBranch,
+		Private:       !r.Public,
+	}, nil
+}
+
+func (repo *BitbucketRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	allCommits := make([]*semrel.RawCommit, 0)
+	query := url.Values{}
+	query.Set("until", toSha)
+	if fromSha != "" {
+		query.Set("since", fromSha)
+	}
+	err := repo.paginate(repo.repoPath("/commits"), query, func(values json.RawMessage) (bool, error) {
+		var commits []*bitbucketCommit
+		if err := json.Unmarshal(values, &commits); err != nil {
+			return false, err
+		}
+		for _, commit := range commits {
+			if commit.ID == fromSha {
+				return false, nil
+			}
+			parents := make([]string, len(commit.Parents))
+			for i, p := range commit.Parents {
+				parents[i] = p.ID
+			}
+			rawCommit := &semrel.RawCommit{
+				SHA:        commit.ID,
+				RawMessage: commit.Message,
+				Author:     commit.Author.toSignature(commit.AuthorTimestamp),
+				Committer:  commit.Committer.toSignature(commit.CommitterTimestamp),
+				Parents:    parents,
+			}
+			if repo.commitFiles {
+				files, err := repo.getCommitFiles(commit.ID)
+				if err != nil {
+					return false, err
+				}
+				rawCommit.Files = files
+			}
+			allCommits = append(allCommits, rawCommit)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allCommits, nil
+}
+
+func (repo *BitbucketRepository) getCommitFiles(sha string) ([]string, error) {
+	files := make([]string, 0)
+	err := repo.paginate(repo.repoPath("/commits/%s/changes", url.PathEscape(sha)), url.Values{}, func(values json.RawMessage) (bool, error) {
+		var changes []*bitbucketChange
+		if err := json.Unmarshal(values, &changes); err != nil {
+			return false, err
+		}
+		for _, change := range changes {
+			files = append(files, change.Path.ToString)
+			if change.SrcPath != nil {
+				files = append(files, change.SrcPath.ToString)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (repo *BitbucketRepository) GetReleases(rawRe string) ([]*semrel.Release, error) {
+	re := regexp.MustCompile(rawRe)
+	allReleases := make([]*semrel.Release, 0)
+	err := repo.paginate(repo.repoPath("/tags"), url.Values{}, func(values json.RawMessage) (bool, error) {
+		var tags []*bitbucketTag
+		if err := json.Unmarshal(values, &tags); err != nil {
+			return false, err
+		}
+		for _, tag := range tags {
+			if rawRe != "" && !re.MatchString(tag.DisplayID) {
+				continue
+			}
+			version, err := semrel.ParseTagVersion(repo.versionScheme, re, tag.DisplayID)
+			if err != nil {
+				continue
+			}
+			// latestCommit is already peeled for annotated tags
+			allReleases = append(allReleases, &semrel.Release{
+				SHA:     tag.LatestCommit,
+				Version: version,
+			})
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allReleases, nil
+}
+
+func (repo *BitbucketRepository) CreateRelease(release *plugin.CreateReleaseConfig) error {
+	tag := release.TagName()
+	// Bitbucket has no notion of releases, the changelog is stored as tag message
+	return repo.do(http.MethodPost, repo.repoPath("/tags"), &bitbucketCreateTag{
+		Name:       tag,
+		StartPoint: release.SHA,
+		Message:    release.Changelog,
+	}, nil)
+}
+
+func (repo *BitbucketRepository) DeleteRelease(release *plugin.CreateReleaseConfig) error {
+	// tags are deleted through the git API that lives next to the core REST API
+	err := repo.do(http.MethodDelete, "../../git/1.0/"+repo.repoPath("/tags/%s", release.TagName()), nil, nil)
+	var bErr *bitbucketError
+	if errors.As(err, &bErr) && bErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (repo *BitbucketRepository) Name() string {
+	return "bitbucket"
+}
+
+func (repo *BitbucketRepository) Version() string {
+	return PVERSION
+}
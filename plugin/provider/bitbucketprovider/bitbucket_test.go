@@ -0,0 +1,272 @@
+package bitbucketprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBitbucketRepository(t *testing.T) {
+	require := require.New(t)
+
+	var repo *BitbucketRepository
+	repo = &BitbucketRepository{}
+	err := repo.Init(map[string]string{})
+	require.EqualError(err, "bitbucket_baseurl is required")
+
+	repo = &BitbucketRepository{}
+	err = repo.Init(map[string]string{
+		"bitbucket_baseurl": "https://bitbucket.example.com",
+	})
+	require.EqualError(err, "bitbucket token missing")
+
+	repo = &BitbucketRepository{}
+	err = repo.Init(map[string]string{
+		"bitbucket_baseurl": "https://bitbucket.example.com",
+		"token":             "token",
+	})
+	require.EqualError(err, "invalid slug")
+
+	repo = &BitbucketRepository{}
+	err = repo.Init(map[string]string{
+		"bitbucket_baseurl": "https://bitbucket.example.com/",
+		"bitbucket_project": "PROJ",
+		"bitbucket_repo":    "test-repo",
+		"token":             "token",
+	})
+	require.NoError(err)
+	require.Equal("https://bitbucket.example.com/rest/api/1.0/", repo.baseURL.String())
+	require.Equal("PROJ", repo.project)
+	require.Equal("test-repo", repo.repo)
+}
+
+func createBitbucketCommit(sha, message string) *bitbucketCommit {
+	return &bitbucketCommit{ID: sha, Message: message}
+}
+
+func createBitbucketTag(name, sha string) *bitbucketTag {
+	return &bitbucketTag{ID: "refs/tags/" + name, DisplayID: name, LatestCommit: sha}
+}
+
+var (
+	BITBUCKET_COMMITS = []*bitbucketCommit{
+		createBitbucketCommit("1111", "feat: to"),
+		createBitbucketCommit("abcd", "feat(app): new feature"),
+		createBitbucketCommit("dcba", "Fix: bug"),
+		createBitbucketCommit("cdba", "Initial commit"),
+		createBitbucketCommit("efcd", "chore: break\nBREAKING CHANGE: breaks everything"),
+	}
+	BITBUCKET_TAGS = []*bitbucketTag{
+		createBitbucketTag("test-tag", "deadbeef"),
+		createBitbucketTag("v1.0.0", "deadbeef"),
+		createBitbucketTag("v2.0.0", "deadbeef"),
+		createBitbucketTag("v2.1.0-beta", "deadbeef"),
+		createBitbucketTag("v3.0.0-beta.2", "deadbeef"),
+		createBitbucketTag("v3.0.0-beta.1", "deadbeef"),
+		createBitbucketTag("2020.04.19", "deadbeef"),
+	}
+)
+
+// writePage serves values in pages of two items.
+//
+//nolint:errcheck
+func writePage(w http.ResponseWriter, r *http.Request, values []interface{}) {
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	end := start + 2
+	if end > len(values) {
+		end = len(values)
+	}
+	data, _ := json.Marshal(values[start:end])
+	json.NewEncoder(w).Encode(bitbucketPage{
+		IsLastPage:    end == len(values),
+		NextPageStart: end,
+		Values:        data,
+	})
+}
+
+//nolint:errcheck
+func bitbucketHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	const repoPath = "/rest/api/1.0/projects/PROJ/repos/test-repo"
+	if r.Method == "GET" && r.URL.Path == repoPath {
+		fmt.Fprint(w, `{"slug":"test-repo","public":false,"project":{"key":"PROJ"}}`)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath+"/default-branch" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath+"/branches/default" {
+		fmt.Fprint(w, `{"id":"refs/heads/develop","displayId":"develop"}`)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath+"/commits" {
+		if r.URL.Query().Get("until") != "1111" || r.URL.Query().Get("since") != "2222" {
+			http.Error(w, "invalid range", http.StatusBadRequest)
+			return
+		}
+		values := make([]interface{}, len(BITBUCKET_COMMITS))
+		for i, c := range BITBUCKET_COMMITS {
+			values[i] = c
+		}
+		writePage(w, r, values)
+		return
+	}
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, repoPath+"/commits/") && strings.HasSuffix(r.URL.Path, "/changes") {
+		sha := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, repoPath+"/commits/"), "/changes")
+		writePage(w, r, []interface{}{
+			&bitbucketChange{Path: bitbucketPath{ToString: sha + "/main.go"}},
+			&bitbucketChange{Path: bitbucketPath{ToString: sha + "/new.go"}, SrcPath: &bitbucketPath{ToString: sha + "/old.go"}},
+		})
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == repoPath+"/tags" {
+		values := make([]interface{}, len(BITBUCKET_TAGS))
+		for i, tag := range BITBUCKET_TAGS {
+			values[i] = tag
+		}
+		writePage(w, r, values)
+		return
+	}
+	if r.Method == "POST" && r.URL.Path == repoPath+"/tags" {
+		var data bitbucketCreateTag
+		json.NewDecoder(r.Body).Decode(&data)
+		r.Body.Close()
+		if data.Name != "v2.0.0" || data.StartPoint != "deadbeef" || data.Message != "changelog" {
+			http.Error(w, "invalid tag", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "{}")
+		return
+	}
+	http.Error(w, "invalid route", http.StatusNotImplemented)
+}
+
+func getNewBitbucketTestRepo(t *testing.T) (*BitbucketRepository, *httptest.Server) {
+	ts := httptest.NewServer(http.HandlerFunc(bitbucketHandler))
+	repo := &BitbucketRepository{}
+	err := repo.Init(map[string]string{
+		"bitbucket_baseurl": ts.URL,
+		"slug":              "PROJ/test-repo",
+		"token":             "token",
+	})
+	require.NoError(t, err)
+	return repo, ts
+}
+
+func TestBitbucketGetInfo(t *testing.T) {
+	repo, ts := getNewBitbucketTestRepo(t)
+	defer ts.Close()
+	repoInfo, err := repo.GetInfo()
+	require.NoError(t, err)
+	require.Equal(t, "develop", repoInfo.DefaultBranch)
+	require.Equal(t, "PROJ", repoInfo.Owner)
+	require.Equal(t, "test-repo", repoInfo.Repo)
+	require.True(t, repoInfo.Private)
+}
+
+func TestBitbucketGetCommits(t *testing.T) {
+	repo, ts := getNewBitbucketTestRepo(t)
+	defer ts.Close()
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, len(BITBUCKET_COMMITS))
+
+	for i, c := range commits {
+		require.Equal(t, c.SHA, BITBUCKET_COMMITS[i].ID)
+		require.Equal(t, c.RawMessage, BITBUCKET_COMMITS[i].Message)
+	}
+}
+
+func TestBitbucketGetCommitsWithFiles(t *testing.T) {
+	repo, ts := getNewBitbucketTestRepo(t)
+	defer ts.Close()
+	repo.commitFiles = true
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, len(BITBUCKET_COMMITS))
+
+	for _, c := range commits {
+		require.Equal(t, []string{c.SHA + "/main.go", c.SHA + "/new.go", c.SHA + "/old.go"}, c.Files)
+	}
+}
+
+func TestBitbucketGetReleases(t *testing.T) {
+	repo, ts := getNewBitbucketTestRepo(t)
+	defer ts.Close()
+
+	testCases := []struct {
+		vrange          string
+		re              string
+		expectedSHA     string
+		expectedVersion string
+	}{
+		{"", "", "deadbeef", "2020.4.19"},
+		{"", "^v[0-9]*", "deadbeef", "2.0.0"},
+		{"2-beta", "", "deadbeef", "2.1.0-beta"},
+		{"3-beta", "", "deadbeef", "3.0.0-beta.2"},
+		{"4-beta", "", "deadbeef", "4.0.0-beta"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("VersionRange: %s, RE: %s", tc.vrange, tc.re), func(t *testing.T) {
+			releases, err := repo.GetReleases(tc.re)
+			require.NoError(t, err)
+			release, err := semrel.GetLatestReleaseFromReleases(releases, tc.vrange)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSHA, release.SHA)
+			require.Equal(t, tc.expectedVersion, release.Version)
+		})
+	}
+}
+
+func TestBitbucketCreateRelease(t *testing.T) {
+	repo, ts := getNewBitbucketTestRepo(t)
+	defer ts.Close()
+	err := repo.CreateRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "changelog"})
+	require.NoError(t, err)
+}
+
+func TestBitbucketDeleteRelease(t *testing.T) {
+	require := require.New(t)
+	deleted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/rest/git/1.0/projects/PROJ/repos/test-repo/tags/") {
+			deleted = append(deleted, r.URL.Path)
+			if strings.HasSuffix(r.URL.Path, "/v3.0.0") {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		bitbucketHandler(w, r)
+	}))
+	defer ts.Close()
+	repo := &BitbucketRepository{}
+	err := repo.Init(map[string]string{
+		"bitbucket_baseurl": ts.URL,
+		"slug":              "PROJ/test-repo",
+		"token":             "token",
+	})
+	require.NoError(err)
+
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0"}))
+	// tags that were never created are ignored
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "3.0.0"}))
+	require.Equal([]string{
+		"/rest/git/1.0/projects/PROJ/repos/test-repo/tags/v2.0.0",
+		"/rest/git/1.0/projects/PROJ/repos/test-repo/tags/v3.0.0",
+	}, deleted)
+}
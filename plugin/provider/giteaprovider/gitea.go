@@ -0,0 +1,349 @@
+package giteaprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+func init() {
+	plugin.RegisterProvider(&GiteaRepository{})
+}
+
+var PVERSION = "dev"
+
+type GiteaRepository struct {
+	owner   string
+	repo    string
+	token   string
+	baseURL *url.URL
+	client  *http.Client
+
+	commitFiles   bool
+	versionScheme semrel.VersionScheme
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaRepo struct {
+	Name          string     `json:"name"`
+	Owner         *giteaUser `json:"owner"`
+	DefaultBranch string     `json:"default_branch"`
+	Private       bool       `json:"private"`
+}
+
+type giteaCommitUser struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+type giteaCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message   string           `json:"message"`
+		Author    *giteaCommitUser `json:"author"`
+		Committer *giteaCommitUser `json:"committer"`
+	} `json:"commit"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+	Files []struct {
+		Filename string `json:"filename"`
+	} `json:"files"`
+}
+
+func (u *giteaCommitUser) toSignature() *semrel.Signature {
+	if u == nil {
+		return nil
+	}
+	return &semrel.Signature{Name: u.Name, Email: u.Email, Date: u.Date}
+}
+
+type giteaObject struct {
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+type giteaRef struct {
+	Ref    string       `json:"ref"`
+	Object *giteaObject `json:"object"`
+}
+
+type giteaTag struct {
+	Tag    string       `json:"tag"`
+	SHA    string       `json:"sha"`
+	Object *giteaObject `json:"object"`
+}
+
+type giteaCreateRelease struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+type giteaError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *giteaError) Error() string {
+	return fmt.Sprintf("gitea: %d %s", e.StatusCode, e.Message)
+}
+
+func (repo *GiteaRepository) Init(config map[string]string) error {
+	baseURL := config["gitea_baseurl"]
+	if baseURL == "" {
+		baseURL = os.Getenv("GITEA_SERVER_URL")
+	}
+	if baseURL == "" {
+		return errors.New("gitea_baseurl is required")
+	}
+	token := config["token"]
+	if token == "" {
+		token = os.Getenv("GITEA_TOKEN")
+	}
+	if token == "" {
+		return errors.New("gitea token missing")
+	}
+
+	slug := config["slug"]
+	if slug == "" {
+		slug = os.Getenv("GITEA_REPOSITORY")
+	}
+	if split := strings.SplitN(slug, "/", 2); len(split) == 2 {
+		repo.owner = split[0]
+		repo.repo = split[1]
+	}
+	if owner := config["gitea_owner"]; owner != "" {
+		repo.owner = owner
+	}
+	if name := config["gitea_repo"]; name != "" {
+		repo.repo = name
+	}
+	if repo.owner == "" || repo.repo == "" {
+		return errors.New("invalid slug")
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/api/v1/")
+	if err != nil {
+		return fmt.Errorf("invalid gitea_baseurl: %w", err)
+	}
+	repo.baseURL = u
+	repo.token = token
+	repo.client = http.DefaultClient
+	repo.commitFiles = config["commit_files"] == "true"
+	versionScheme, err := semrel.NewVersionScheme(config["version_scheme"], config["calver_format"])
+	if err != nil {
+		return err
+	}
+	repo.versionScheme = versionScheme
+	return nil
+}
+
+// do sends an authenticated request to the Gitea API and decodes the JSON response into out.
+func (repo *GiteaRepository) do(method, path string, body, out interface{}) (*http.Response, error) {
+	u, err := repo.baseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+repo.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := repo.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return resp, &giteaError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+func (repo *GiteaRepository) repoPath(format string, a ...interface{}) string {
+	return fmt.Sprintf("repos/%s/%s", url.PathEscape(repo.owner), url.PathEscape(repo.repo)) + fmt.Sprintf(format, a...)
+}
+
+func (repo *GiteaRepository) GetInfo() (*plugin.RepositoryInfo, error) {
+	r := &giteaRepo{}
+	if _, err := repo.do(http.MethodGet, repo.repoPath(""), nil, r); err != nil {
+		return nil, err
+	}
+	owner := repo.owner
+	if r.Owner != nil && r.Owner.Login != "" {
+		owner = r.Owner.Login
+	}
+	return &plugin.RepositoryInfo{
+		Owner:         owner,
+		Repo:          r.Name,
+		DefaultBranch: r.DefaultBranch,
+		Private:       r.Private,
+	}, nil
+}
+
+func (repo *GiteaRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	allCommits := make([]*semrel.RawCommit, 0)
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("sha", toSha)
+		query.Set("page", fmt.Sprint(page))
+		query.Set("limit", "50")
+		// skip the expensive per commit data that is not needed here
+		query.Set("stat", "false")
+		query.Set("verification", "false")
+		query.Set("files", fmt.Sprint(repo.commitFiles))
+
+		var commits []*giteaCommit
+		resp, err := repo.do(http.MethodGet, repo.repoPath("/commits?%s", query.Encode()), nil, &commits)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if commit.SHA == fromSha {
+				return allCommits, nil
+			}
+			parents := make([]string, len(commit.Parents))
+			for i, p := range commit.Parents {
+				parents[i] = p.SHA
+			}
+			rawCommit := &semrel.RawCommit{
+				SHA:        commit.SHA,
+				RawMessage: commit.Commit.Message,
+				Author:     commit.Commit.Author.toSignature(),
+				Committer:  commit.Commit.Committer.toSignature(),
+				Parents:    parents,
+			}
+			if repo.commitFiles {
+				rawCommit.Files = make([]string, len(commit.Files))
+				for i, f := range commit.Files {
+					rawCommit.Files[i] = f.Filename
+				}
+			}
+			allCommits = append(allCommits, rawCommit)
+		}
+		if len(commits) == 0 || resp.Header.Get("X-HasMore") != "true" {
+			break
+		}
+	}
+	return allCommits, nil
+}
+
+func (repo *GiteaRepository) GetReleases(rawRe string) ([]*semrel.Release, error) {
+	re := regexp.MustCompile(rawRe)
+	allReleases := make([]*semrel.Release, 0)
+
+	var refs []*giteaRef
+	_, err := repo.do(http.MethodGet, repo.repoPath("/git/refs/tags"), nil, &refs)
+	var gErr *giteaError
+	if errors.As(err, &gErr) && gErr.StatusCode == http.StatusNotFound {
+		return allReleases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range refs {
+		tag := strings.TrimPrefix(r.Ref, "refs/tags/")
+		if rawRe != "" && !re.MatchString(tag) {
+			continue
+		}
+		if r.Object == nil || (r.Object.Type != "commit" && r.Object.Type != "tag") {
+			continue
+		}
+		version, err := semrel.ParseTagVersion(repo.versionScheme, re, tag)
+		if err != nil {
+			continue
+		}
+		foundSha := r.Object.SHA
+		// resolve annotated tag
+		if r.Object.Type == "tag" {
+			resTag := &giteaTag{}
+			if _, err := repo.do(http.MethodGet, repo.repoPath("/git/tags/%s", foundSha), nil, resTag); err != nil {
+				continue
+			}
+			if resTag.Object == nil || resTag.Object.Type != "commit" {
+				continue
+			}
+			foundSha = resTag.Object.SHA
+		}
+		allReleases = append(allReleases, &semrel.Release{SHA: foundSha, Version: version})
+	}
+
+	return allReleases, nil
+}
+
+func (repo *GiteaRepository) CreateRelease(release *plugin.CreateReleaseConfig) error {
+	tag := release.TagName()
+	isPrerelease := release.Prerelease || repo.versionScheme.IsPrerelease(release.NewVersion)
+
+	target := release.SHA
+	if target == "" {
+		target = release.Branch
+	}
+	opts := &giteaCreateRelease{
+		TagName:         tag,
+		TargetCommitish: target,
+		Name:            tag,
+		Body:            release.Changelog,
+		Prerelease:      isPrerelease,
+	}
+	_, err := repo.do(http.MethodPost, repo.repoPath("/releases"), opts, nil)
+	return err
+}
+
+func (repo *GiteaRepository) DeleteRelease(release *plugin.CreateReleaseConfig) error {
+	tag := url.PathEscape(release.TagName())
+	for _, path := range []string{repo.repoPath("/releases/tags/%s", tag), repo.repoPath("/tags/%s", tag)} {
+		_, err := repo.do(http.MethodDelete, path, nil, nil)
+		var gErr *giteaError
+		if err != nil && !(errors.As(err, &gErr) && gErr.StatusCode == http.StatusNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *GiteaRepository) Name() string {
+	return "gitea"
+}
+
+func (repo *GiteaRepository) Version() string {
+	return PVERSION
+}
@@ -0,0 +1,295 @@
+package giteaprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGiteaRepository(t *testing.T) {
+	require := require.New(t)
+
+	var repo *GiteaRepository
+	repo = &GiteaRepository{}
+	err := repo.Init(map[string]string{})
+	require.EqualError(err, "gitea_baseurl is required")
+
+	repo = &GiteaRepository{}
+	err = repo.Init(map[string]string{
+		"gitea_baseurl": "https://gitea.example.com",
+	})
+	require.EqualError(err, "gitea token missing")
+
+	repo = &GiteaRepository{}
+	err = repo.Init(map[string]string{
+		"gitea_baseurl": "https://gitea.example.com",
+		"token":         "token",
+	})
+	require.EqualError(err, "invalid slug")
+
+	repo = &GiteaRepository{}
+	err = repo.Init(map[string]string{
+		"gitea_baseurl": "https://gitea.example.com/",
+		"slug":          "owner/test-repo",
+		"token":         "token",
+	})
+	require.NoError(err)
+	require.Equal("https://gitea.example.com/api/v1/", repo.baseURL.String())
+
+	repo = &GiteaRepository{}
+	err = repo.Init(map[string]string{
+		"gitea_baseurl": "https://gitea.example.com",
+		"gitea_owner":   "other",
+		"gitea_repo":    "other-repo",
+		"token":         "token",
+	})
+	require.NoError(err)
+	require.Equal("other", repo.owner)
+	require.Equal("other-repo", repo.repo)
+}
+
+func createGiteaCommit(sha, message string) *giteaCommit {
+	c := &giteaCommit{SHA: sha}
+	c.Commit.Message = message
+	return c
+}
+
+func createGiteaRef(ref, sha string) *giteaRef {
+	return &giteaRef{Ref: ref, Object: &giteaObject{Type: "commit", SHA: sha}}
+}
+
+func createGiteaRefWithTag(ref, sha string) *giteaRef {
+	return &giteaRef{Ref: ref, Object: &giteaObject{Type: "tag", SHA: sha}}
+}
+
+var (
+	GITEA_REPO = giteaRepo{
+		Name:          "test-repo",
+		Owner:         &giteaUser{Login: "owner"},
+		DefaultBranch: "main",
+		Private:       true,
+	}
+	GITEA_COMMITS = []*giteaCommit{
+		createGiteaCommit("abcd", "feat(app): new new feature"),
+		createGiteaCommit("1111", "feat: to"),
+		createGiteaCommit("abcd", "feat(app): new feature"),
+		createGiteaCommit("dcba", "Fix: bug"),
+		createGiteaCommit("cdba", "Initial commit"),
+		createGiteaCommit("efcd", "chore: break\nBREAKING CHANGE: breaks everything"),
+		createGiteaCommit("2222", "feat: from"),
+		createGiteaCommit("beef", "fix: test"),
+	}
+	GITEA_TAGS = []*giteaRef{
+		createGiteaRef("refs/tags/test-tag", "deadbeef"),
+		createGiteaRef("refs/tags/v1.0.0", "deadbeef"),
+		createGiteaRef("refs/tags/v2.0.0", "deadbeef"),
+		createGiteaRef("refs/tags/v2.1.0-beta", "deadbeef"),
+		createGiteaRef("refs/tags/v3.0.0-beta.2", "deadbeef"),
+		createGiteaRef("refs/tags/v3.0.0-beta.1", "deadbeef"),
+		createGiteaRef("refs/tags/2020.04.19", "deadbeef"),
+		createGiteaRefWithTag("refs/tags/v1.1.1", "12345678"),
+	}
+)
+
+//nolint:errcheck
+func giteaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "token token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == "/api/v1/repos/owner/test-repo" {
+		json.NewEncoder(w).Encode(GITEA_REPO)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == "/api/v1/repos/owner/test-repo/commits" {
+		toSha := r.URL.Query().Get("sha")
+		skip := 0
+		for i, commit := range GITEA_COMMITS {
+			if commit.SHA == toSha {
+				skip = i
+				break
+			}
+		}
+		// serve two commits per page to exercise the pagination
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		start := skip + (page-1)*2
+		end := start + 2
+		if end >= len(GITEA_COMMITS) {
+			end = len(GITEA_COMMITS)
+		} else {
+			w.Header().Set("X-HasMore", "true")
+		}
+		json.NewEncoder(w).Encode(GITEA_COMMITS[start:end])
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == "/api/v1/repos/owner/test-repo/git/refs/tags" {
+		json.NewEncoder(w).Encode(GITEA_TAGS)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == "/api/v1/repos/owner/test-repo/git/tags/12345678" {
+		json.NewEncoder(w).Encode(giteaTag{
+			Tag:    "v1.1.1",
+			SHA:    "12345678",
+			Object: &giteaObject{Type: "commit", SHA: "deadbeef"},
+		})
+		return
+	}
+	if r.Method == "POST" && r.URL.Path == "/api/v1/repos/owner/test-repo/releases" {
+		var data giteaCreateRelease
+		json.NewDecoder(r.Body).Decode(&data)
+		r.Body.Close()
+		if data.TagName != "v2.0.0" || data.TargetCommitish != "deadbeef" {
+			http.Error(w, "invalid tag name or target", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "{}")
+		return
+	}
+	http.Error(w, "invalid route", http.StatusNotImplemented)
+}
+
+func getNewGiteaTestRepo(t *testing.T) (*GiteaRepository, *httptest.Server) {
+	ts := httptest.NewServer(http.HandlerFunc(giteaHandler))
+	repo := &GiteaRepository{}
+	err := repo.Init(map[string]string{
+		"gitea_baseurl": ts.URL,
+		"slug":          "owner/test-repo",
+		"token":         "token",
+	})
+	require.NoError(t, err)
+	return repo, ts
+}
+
+func TestGiteaGetInfo(t *testing.T) {
+	repo, ts := getNewGiteaTestRepo(t)
+	defer ts.Close()
+	repoInfo, err := repo.GetInfo()
+	require.NoError(t, err)
+	require.Equal(t, "main", repoInfo.DefaultBranch)
+	require.Equal(t, "owner", repoInfo.Owner)
+	require.Equal(t, "test-repo", repoInfo.Repo)
+	require.True(t, repoInfo.Private)
+}
+
+func TestGiteaGetCommits(t *testing.T) {
+	repo, ts := getNewGiteaTestRepo(t)
+	defer ts.Close()
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+
+	for i, c := range commits {
+		idxOff := i + 1
+		require.Equal(t, c.SHA, GITEA_COMMITS[idxOff].SHA)
+		require.Equal(t, c.RawMessage, GITEA_COMMITS[idxOff].Commit.Message)
+	}
+}
+
+func TestGiteaGetCommitsWithFiles(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("files") != "true" {
+			http.Error(w, "files not requested", http.StatusBadRequest)
+			return
+		}
+		commit := createGiteaCommit("abcd", "feat(api): endpoint")
+		commit.Files = append(commit.Files, struct {
+			Filename string `json:"filename"`
+		}{"api/main.go"})
+		json.NewEncoder(w).Encode([]*giteaCommit{commit}) //nolint:errcheck
+	}))
+	defer ts.Close()
+	repo := &GiteaRepository{}
+	err := repo.Init(map[string]string{
+		"gitea_baseurl": ts.URL,
+		"slug":          "owner/test-repo",
+		"token":         "token",
+		"commit_files":  "true",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("", "abcd")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, []string{"api/main.go"}, commits[0].Files)
+}
+
+func TestGiteaGetReleases(t *testing.T) {
+	repo, ts := getNewGiteaTestRepo(t)
+	defer ts.Close()
+
+	testCases := []struct {
+		vrange          string
+		re              string
+		expectedSHA     string
+		expectedVersion string
+	}{
+		{"", "", "deadbeef", "2020.4.19"},
+		{"", "^v[0-9]*", "deadbeef", "2.0.0"},
+		{"2-beta", "", "deadbeef", "2.1.0-beta"},
+		{"3-beta", "", "deadbeef", "3.0.0-beta.2"},
+		{"4-beta", "", "deadbeef", "4.0.0-beta"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("VersionRange: %s, RE: %s", tc.vrange, tc.re), func(t *testing.T) {
+			releases, err := repo.GetReleases(tc.re)
+			require.NoError(t, err)
+			release, err := semrel.GetLatestReleaseFromReleases(releases, tc.vrange)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSHA, release.SHA)
+			require.Equal(t, tc.expectedVersion, release.Version)
+		})
+	}
+
+	releases, err := repo.GetReleases("^v1\\.1")
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	require.Equal(t, "deadbeef", releases[0].SHA)
+}
+
+func TestGiteaCreateRelease(t *testing.T) {
+	repo, ts := getNewGiteaTestRepo(t)
+	defer ts.Close()
+	err := repo.CreateRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+}
+
+func TestGiteaDeleteRelease(t *testing.T) {
+	require := require.New(t)
+	deleted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL.Path)
+			if r.URL.Path == "/api/v1/repos/owner/test-repo/releases/tags/v2.0.0" {
+				// the tag was created, but not the release
+				http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		giteaHandler(w, r)
+	}))
+	defer ts.Close()
+	repo := &GiteaRepository{}
+	err := repo.Init(map[string]string{
+		"gitea_baseurl": ts.URL,
+		"slug":          "owner/test-repo",
+		"token":         "token",
+	})
+	require.NoError(err)
+
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0"}))
+	require.Equal([]string{
+		"/api/v1/repos/owner/test-repo/releases/tags/v2.0.0",
+		"/api/v1/repos/owner/test-repo/tags/v2.0.0",
+	}, deleted)
+}
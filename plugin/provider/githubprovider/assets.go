@@ -0,0 +1,143 @@
+package githubprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+var assetRetryDelay = time.Second
+
+// detectContentType guesses the media type of an asset by its extension and falls back to sniffing the content.
+func detectContentType(file string) (string, error) {
+	if mediaType := mime.TypeByExtension(filepath.Ext(file)); mediaType != "" {
+		return mediaType, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, err := f.Read(buf)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// writeChecksums creates a sha256sum compatible file of all assets in a temporary directory.
+func writeChecksums(files []string, name string) (string, error) {
+	content := ""
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		content += fmt.Sprintf("%s  %s\n", hex.EncodeToString(h.Sum(nil)), filepath.Base(file))
+	}
+	dir, err := ioutil.TempDir("", "semrel-assets")
+	if err != nil {
+		return "", err
+	}
+	checksumFile := filepath.Join(dir, name)
+	return checksumFile, ioutil.WriteFile(checksumFile, []byte(content), 0644)
+}
+
+// findAsset returns the asset of the release with the given name.
+func (repo *GitHubRepository) findAsset(releaseID int64, name string) (*github.ReleaseAsset, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		assets, resp, err := repo.client.Repositories.ListReleaseAssets(context.Background(), repo.owner, repo.repo, releaseID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			if asset.GetName() == name {
+				return asset, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (repo *GitHubRepository) uploadAsset(releaseID int64, file string) error {
+	name := filepath.Base(file)
+	mediaType, err := detectContentType(file)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, _, err = repo.client.Repositories.UploadReleaseAsset(context.Background(), repo.owner, repo.repo, releaseID, &github.UploadOptions{
+		Name:      name,
+		MediaType: mediaType,
+	}, f)
+	return err
+}
+
+// uploadAssetWithRetry uploads an asset and retries failed uploads. Partially uploaded assets
+// are left behind by GitHub in the starter state and have to be removed before the next attempt.
+func (repo *GitHubRepository) uploadAssetWithRetry(releaseID int64, file string) error {
+	var err error
+	for attempt := 0; attempt <= repo.assetRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * assetRetryDelay)
+			asset, ferr := repo.findAsset(releaseID, filepath.Base(file))
+			if ferr != nil {
+				return ferr
+			}
+			if asset != nil && asset.GetState() == "uploaded" {
+				return nil
+			}
+			if asset != nil {
+				if _, derr := repo.client.Repositories.DeleteReleaseAsset(context.Background(), repo.owner, repo.repo, asset.GetID()); derr != nil {
+					return derr
+				}
+			}
+		}
+		if err = repo.uploadAsset(releaseID, file); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("upload asset %s: %w", file, err)
+}
+
+func (repo *GitHubRepository) uploadAssets(releaseID int64, files []string) error {
+	if repo.assetChecksums != "" {
+		checksumFile, err := writeChecksums(files, repo.assetChecksums)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(filepath.Dir(checksumFile))
+		files = append(files, checksumFile)
+	}
+	for _, file := range files {
+		if err := repo.uploadAssetWithRetry(releaseID, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
-	"github.com/Masterminds/semver/v3"
 	"github.com/duanqy/semantic-release/pkg/plugin"
 	"github.com/duanqy/semantic-release/pkg/semrel"
 	"github.com/google/go-github/v32/github"
@@ -22,9 +23,16 @@ func init() {
 var PVERSION = "dev"
 
 type GitHubRepository struct {
-	owner  string
-	repo   string
-	client *github.Client
+	owner          string
+	repo           string
+	client         *github.Client
+	httpClient     *http.Client
+	useGraphQL     bool
+	commitFiles    bool
+	pullRequests   bool
+	versionScheme  semrel.VersionScheme
+	assetChecksums string
+	assetRetries   int
 }
 
 func (repo *GitHubRepository) Init(config map[string]string) error {
@@ -52,7 +60,25 @@ func (repo *GitHubRepository) Init(config map[string]string) error {
 	split := strings.Split(slug, "/")
 	repo.owner = split[0]
 	repo.repo = split[1]
+	repo.useGraphQL = config["github_graphql"] == "true"
+	repo.commitFiles = config["commit_files"] == "true"
+	repo.pullRequests = config["pull_requests"] == "true"
+	versionScheme, err := semrel.NewVersionScheme(config["version_scheme"], config["calver_format"])
+	if err != nil {
+		return err
+	}
+	repo.versionScheme = versionScheme
+	repo.assetChecksums = config["asset_checksums"]
+	repo.assetRetries = 3
+	if retries := config["asset_retries"]; retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return errors.New("invalid asset_retries")
+		}
+		repo.assetRetries = n
+	}
 	oauthClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	repo.httpClient = oauthClient
 	if gheHost != "" {
 		gheUrl := fmt.Sprintf("https://%s/api/v3/", gheHost)
 		rClient, err := github.NewEnterpriseClient(gheUrl, gheUrl, oauthClient)
@@ -80,6 +106,31 @@ func (repo *GitHubRepository) GetInfo() (*plugin.RepositoryInfo, error) {
 }
 
 func (repo *GitHubRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	var commits []*semrel.RawCommit
+	var err error
+	if repo.useGraphQL {
+		commits, err = repo.getCommitsGraphQL(fromSha, toSha)
+	} else {
+		commits, err = repo.getCommits(fromSha, toSha)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if repo.commitFiles {
+		if err := repo.fillCommitFiles(commits); err != nil {
+			return nil, err
+		}
+	}
+	// the GraphQL history already contains the pull requests
+	if repo.pullRequests && !repo.useGraphQL {
+		if err := repo.fillPullRequests(commits); err != nil {
+			return nil, err
+		}
+	}
+	return commits, nil
+}
+
+func (repo *GitHubRepository) getCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
 	allCommits := make([]*semrel.RawCommit, 0)
 	opts := &github.CommitsListOptions{
 		SHA:         toSha,
@@ -97,9 +148,16 @@ func (repo *GitHubRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCo
 				done = true
 				break
 			}
+			parents := make([]string, len(commit.Parents))
+			for i, p := range commit.Parents {
+				parents[i] = p.GetSHA()
+			}
 			allCommits = append(allCommits, &semrel.RawCommit{
 				SHA:        sha,
 				RawMessage: commit.Commit.GetMessage(),
+				Author:     toSignature(commit.Commit.GetAuthor()),
+				Committer:  toSignature(commit.Commit.GetCommitter()),
+				Parents:    parents,
 			})
 		}
 		if done || resp.NextPage == 0 {
@@ -111,7 +169,48 @@ func (repo *GitHubRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCo
 	return allCommits, nil
 }
 
+// fillCommitFiles fetches the changed files of every commit, since neither the
+// commit list nor the GraphQL history contains them.
+func (repo *GitHubRepository) fillCommitFiles(commits []*semrel.RawCommit) error {
+	for _, commit := range commits {
+		fullCommit, _, err := repo.client.Repositories.GetCommit(context.Background(), repo.owner, repo.repo, commit.SHA)
+		if err != nil {
+			return err
+		}
+		commit.Files = make([]string, 0, len(fullCommit.Files))
+		for _, file := range fullCommit.Files {
+			commit.Files = append(commit.Files, file.GetFilename())
+		}
+	}
+	return nil
+}
+
+// fillPullRequests looks up the pull request of every commit, the commit list
+// of the REST API does not contain it.
+func (repo *GitHubRepository) fillPullRequests(commits []*semrel.RawCommit) error {
+	for _, commit := range commits {
+		prs, _, err := repo.client.PullRequests.ListPullRequestsWithCommit(context.Background(), repo.owner, repo.repo, commit.SHA, nil)
+		if err != nil {
+			return err
+		}
+		if len(prs) > 0 {
+			commit.PullRequest = prs[0].GetNumber()
+		}
+	}
+	return nil
+}
+
+func toSignature(author *github.CommitAuthor) *semrel.Signature {
+	if author == nil {
+		return nil
+	}
+	return &semrel.Signature{Name: author.GetName(), Email: author.GetEmail(), Date: author.GetDate()}
+}
+
 func (repo *GitHubRepository) GetReleases(rawRe string) ([]*semrel.Release, error) {
+	if repo.useGraphQL {
+		return repo.getReleasesGraphQL(rawRe)
+	}
 	re := regexp.MustCompile(rawRe)
 	allReleases := make([]*semrel.Release, 0)
 	opts := &github.ReferenceListOptions{Ref: "tags", ListOptions: github.ListOptions{PerPage: 100}}
@@ -144,11 +243,11 @@ func (repo *GitHubRepository) GetReleases(rawRe string) ([]*semrel.Release, erro
 				}
 				foundSha = resTag.Object.GetSHA()
 			}
-			version, err := semver.NewVersion(tag)
+			version, err := semrel.ParseTagVersion(repo.versionScheme, re, tag)
 			if err != nil {
 				continue
 			}
-			allReleases = append(allReleases, &semrel.Release{SHA: foundSha, Version: version.String()})
+			allReleases = append(allReleases, &semrel.Release{SHA: foundSha, Version: version})
 		}
 		if resp.NextPage == 0 {
 			break
@@ -160,8 +259,17 @@ func (repo *GitHubRepository) GetReleases(rawRe string) ([]*semrel.Release, erro
 }
 
 func (repo *GitHubRepository) CreateRelease(release *plugin.CreateReleaseConfig) error {
-	tag := fmt.Sprintf("v%s", release.NewVersion)
-	isPrerelease := release.Prerelease || semver.MustParse(release.NewVersion).Prerelease() != ""
+	tag := release.TagName()
+	isPrerelease := release.Prerelease || repo.versionScheme.IsPrerelease(release.NewVersion)
+
+	// resolve the assets first to not create a release with missing files
+	assets, err := plugin.ExpandAssets(release.Assets)
+	if err != nil {
+		return err
+	}
+	if err := plugin.CheckAssetNames(assets, repo.assetChecksums); err != nil {
+		return err
+	}
 
 	if release.Branch != release.SHA {
 		ref := "refs/tags/" + tag
@@ -182,8 +290,38 @@ func (repo *GitHubRepository) CreateRelease(release *plugin.CreateReleaseConfig)
 		Body:            &release.Changelog,
 		Prerelease:      &isPrerelease,
 	}
-	_, _, err := repo.client.Repositories.CreateRelease(context.Background(), repo.owner, repo.repo, opts)
-	return err
+	createdRelease, _, err := repo.client.Repositories.CreateRelease(context.Background(), repo.owner, repo.repo, opts)
+	if err != nil {
+		return err
+	}
+	if len(assets) == 0 {
+		return nil
+	}
+	return repo.uploadAssets(createdRelease.GetID(), assets)
+}
+
+func (repo *GitHubRepository) DeleteRelease(release *plugin.CreateReleaseConfig) error {
+	tag := release.TagName()
+	ctx := context.Background()
+	createdRelease, resp, err := repo.client.Repositories.GetReleaseByTag(ctx, repo.owner, repo.repo, tag)
+	if err != nil && !isNotFound(resp) {
+		return err
+	}
+	if err == nil {
+		resp, err = repo.client.Repositories.DeleteRelease(ctx, repo.owner, repo.repo, createdRelease.GetID())
+		if err != nil && !isNotFound(resp) {
+			return err
+		}
+	}
+	resp, err = repo.client.Git.DeleteRef(ctx, repo.owner, repo.repo, "tags/"+tag)
+	if err != nil && !isNotFound(resp) {
+		return err
+	}
+	return nil
+}
+
+func isNotFound(resp *github.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
 }
 
 func (repo *GitHubRepository) Name() string {
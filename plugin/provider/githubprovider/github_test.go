@@ -3,10 +3,16 @@ package githubprovider
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/duanqy/semantic-release/pkg/plugin"
 	"github.com/duanqy/semantic-release/pkg/semrel"
@@ -40,8 +46,15 @@ func TestNewGithubRepository(t *testing.T) {
 	require.Equal("github.enterprise", repo.client.BaseURL.Host)
 }
 
+var commitDate = time.Date(2020, 4, 19, 12, 0, 0, 0, time.UTC)
+
 func createGithubCommit(sha, message string) *github.RepositoryCommit {
-	return &github.RepositoryCommit{SHA: &sha, Commit: &github.Commit{Message: &message}}
+	author := &github.CommitAuthor{Name: github.String("author"), Email: github.String("author@example.com"), Date: &commitDate}
+	return &github.RepositoryCommit{
+		SHA:     &sha,
+		Commit:  &github.Commit{Message: &message, Author: author, Committer: author},
+		Parents: []*github.Commit{{SHA: github.String(sha + "-parent")}},
+	}
 }
 
 var commitType = "commit"
@@ -112,6 +125,22 @@ func githubHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(GITHUB_COMMITS[skip:])
 		return
 	}
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/repos/owner/test-repo/commits/") && strings.HasSuffix(r.URL.Path, "/pulls") {
+		prs := []*github.PullRequest{}
+		if sha := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/repos/owner/test-repo/commits/"), "/pulls"); sha != "dcba" {
+			prs = append(prs, &github.PullRequest{Number: github.Int(42)})
+		}
+		json.NewEncoder(w).Encode(prs)
+		return
+	}
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/repos/owner/test-repo/commits/") {
+		sha := strings.TrimPrefix(r.URL.Path, "/repos/owner/test-repo/commits/")
+		json.NewEncoder(w).Encode(github.RepositoryCommit{
+			SHA:   &sha,
+			Files: []*github.CommitFile{{Filename: github.String(sha + "/main.go")}},
+		})
+		return
+	}
 	if r.Method == "GET" && r.URL.Path == "/repos/owner/test-repo/git/matching-refs/tags" {
 		json.NewEncoder(w).Encode(GITHUB_TAGS)
 		return
@@ -182,6 +211,45 @@ func TestGithubGetCommits(t *testing.T) {
 		idxOff := i + 1
 		require.Equal(t, c.SHA, GITHUB_COMMITS[idxOff].GetSHA())
 		require.Equal(t, c.RawMessage, GITHUB_COMMITS[idxOff].Commit.GetMessage())
+		require.Equal(t, &semrel.Signature{Name: "author", Email: "author@example.com", Date: commitDate}, c.Author)
+		require.Equal(t, c.Author, c.Committer)
+		require.Equal(t, []string{c.SHA + "-parent"}, c.Parents)
+	}
+}
+
+func TestGithubGetCommitsWithFiles(t *testing.T) {
+	repo, ts := getNewGithubTestRepo(t)
+	defer ts.Close()
+	repo.commitFiles = true
+
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+	for _, c := range commits {
+		require.Equal(t, []string{c.SHA + "/main.go"}, c.Files)
+	}
+}
+
+func TestGithubGetCommitsWithPullRequests(t *testing.T) {
+	repo, ts := getNewGithubTestRepo(t)
+	defer ts.Close()
+
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	for _, c := range commits {
+		require.Zero(t, c.PullRequest)
+	}
+
+	repo.pullRequests = true
+	commits, err = repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+	for _, c := range commits {
+		if c.SHA == "dcba" {
+			require.Zero(t, c.PullRequest)
+			continue
+		}
+		require.Equal(t, 42, c.PullRequest)
 	}
 }
 
@@ -189,6 +257,9 @@ func TestGithubGetReleases(t *testing.T) {
 	repo, ts := getNewGithubTestRepo(t)
 	defer ts.Close()
 
+	tagFormat, err := semrel.NewTagFormat(semrel.DefaultTagFormat)
+	require.NoError(t, err)
+
 	testCases := []struct {
 		vrange          string
 		re              string
@@ -197,6 +268,7 @@ func TestGithubGetReleases(t *testing.T) {
 	}{
 		{"", "", "deadbeef", "2020.4.19"},
 		{"", "^v[0-9]*", "deadbeef", "2.0.0"},
+		{"", tagFormat.Regex(""), "deadbeef", "2.0.0"},
 		{"2-beta", "", "deadbeef", "2.1.0-beta"},
 		{"3-beta", "", "deadbeef", "3.0.0-beta.2"},
 		{"4-beta", "", "deadbeef", "4.0.0-beta"},
@@ -220,3 +292,398 @@ func TestGithubCreateRelease(t *testing.T) {
 	err := repo.CreateRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
 	require.NoError(t, err)
 }
+
+type assetServer struct {
+	assets   map[string]*github.ReleaseAsset
+	failed   map[string]bool
+	contents map[string]string
+	nextID   int64
+}
+
+//nolint:errcheck
+func (as *assetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" && r.URL.Path == "/repos/owner/test-repo/releases" {
+		fmt.Fprint(w, `{"id": 1}`)
+		return
+	}
+	if r.Method == "POST" && r.URL.Path == "/repos/owner/test-repo/releases/1/assets" {
+		name := r.URL.Query().Get("name")
+		data, _ := ioutil.ReadAll(r.Body)
+		as.nextID++
+		asset := &github.ReleaseAsset{ID: github.Int64(as.nextID), Name: &name, ContentType: github.String(r.Header.Get("Content-Type"))}
+		as.assets[name] = asset
+		// the first upload of the archive breaks off and leaves a starter asset behind
+		if name == "app.tar.gz" && !as.failed[name] {
+			as.failed[name] = true
+			asset.State = github.String("starter")
+			http.Error(w, "upload failed", http.StatusInternalServerError)
+			return
+		}
+		asset.State = github.String("uploaded")
+		as.contents[name] = string(data)
+		json.NewEncoder(w).Encode(asset)
+		return
+	}
+	if r.Method == "GET" && r.URL.Path == "/repos/owner/test-repo/releases/1/assets" {
+		assets := make([]*github.ReleaseAsset, 0)
+		for _, asset := range as.assets {
+			assets = append(assets, asset)
+		}
+		json.NewEncoder(w).Encode(assets)
+		return
+	}
+	if r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/repos/owner/test-repo/releases/assets/") {
+		for name, asset := range as.assets {
+			if fmt.Sprintf("/repos/owner/test-repo/releases/assets/%d", asset.GetID()) == r.URL.Path {
+				delete(as.assets, name)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	githubHandler(w, r)
+}
+
+func TestGithubCreateReleaseWithAssets(t *testing.T) {
+	require := require.New(t)
+	assetRetryDelay = 0
+
+	dir, err := ioutil.TempDir("", "github-assets")
+	require.NoError(err)
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "app.tar.gz"), []byte("archive"), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "app"), []byte("\x7fELF\x02\x01\x01\x00"), 0644))
+
+	repo := &GitHubRepository{}
+	err = repo.Init(map[string]string{
+		"slug":            "owner/test-repo",
+		"token":           "token",
+		"asset_checksums": "checksums.txt",
+	})
+	require.NoError(err)
+	as := &assetServer{
+		assets:   map[string]*github.ReleaseAsset{},
+		failed:   map[string]bool{},
+		contents: map[string]string{},
+	}
+	ts := httptest.NewServer(as)
+	defer ts.Close()
+	repo.client.BaseURL, _ = url.Parse(ts.URL + "/")
+	repo.client.UploadURL, _ = url.Parse(ts.URL + "/")
+
+	err = repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "2.0.0",
+		SHA:        "deadbeef",
+		Assets:     []string{filepath.Join(dir, "app*"), filepath.Join(dir, "*.txt")},
+	})
+	require.NoError(err)
+
+	require.Len(as.assets, 4)
+	for _, asset := range as.assets {
+		require.Equal("uploaded", asset.GetState())
+	}
+	require.Equal("application/gzip", as.assets["app.tar.gz"].GetContentType())
+	require.Equal("application/octet-stream", as.assets["app"].GetContentType())
+	require.True(strings.HasPrefix(as.assets["notes.txt"].GetContentType(), "text/plain"))
+	require.Equal("archive", as.contents["app.tar.gz"])
+	require.Contains(as.contents["checksums.txt"], "0eb3e36bfb24dcd9bb1d1bece1531216b59539a8fde17ee80224af0653c92aa3  app.tar.gz\n")
+
+	err = repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "2.0.0",
+		SHA:        "deadbeef",
+		Assets:     []string{filepath.Join(dir, "*.zip")},
+	})
+	require.EqualError(err, fmt.Sprintf("asset pattern %s did not match any files", filepath.Join(dir, "*.zip")))
+
+	// assets with the same name would replace each other
+	as.assets = map[string]*github.ReleaseAsset{}
+	for _, platform := range []string{"darwin", "linux"} {
+		require.NoError(os.Mkdir(filepath.Join(dir, platform), 0755))
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, platform, "app"), []byte(platform), 0644))
+	}
+	err = repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "2.0.0",
+		SHA:        "deadbeef",
+		Assets:     []string{filepath.Join(dir, "*", "app")},
+	})
+	require.EqualError(err, fmt.Sprintf("assets %s and %s have the same name app", filepath.Join(dir, "darwin", "app"), filepath.Join(dir, "linux", "app")))
+	require.Empty(as.assets)
+
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "checksums.txt"), []byte("checksums"), 0644))
+	err = repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "2.0.0",
+		SHA:        "deadbeef",
+		Assets:     []string{filepath.Join(dir, "checksums.txt")},
+	})
+	require.EqualError(err, fmt.Sprintf("assets checksums.txt and %s have the same name checksums.txt", filepath.Join(dir, "checksums.txt")))
+}
+
+// graphQLPage returns the slice of items for the cursor and the page info of the next page.
+func graphQLPage(total int, cursor interface{}) (int, int, map[string]interface{}) {
+	const pageSize = 3
+	start := 0
+	if c, ok := cursor.(string); ok {
+		fmt.Sscanf(c, "cursor-%d", &start) //nolint:errcheck
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end, map[string]interface{}{"hasNextPage": end < total, "endCursor": fmt.Sprintf("cursor-%d", end)}
+}
+
+//nolint:errcheck
+func githubGraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != "POST" || r.URL.Path != "/graphql" {
+		http.Error(w, "invalid route", http.StatusNotImplemented)
+		return
+	}
+	var req graphQLRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	r.Body.Close()
+	if req.Variables["owner"] != "owner" || req.Variables["name"] != "test-repo" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]string{{"message": "repository not found"}}})
+		return
+	}
+	switch {
+	case strings.Contains(req.Query, "refs("):
+		start, end, pageInfo := graphQLPage(len(GITHUB_TAGS), req.Variables["cursor"])
+		nodes := make([]map[string]interface{}, 0)
+		for _, ref := range GITHUB_TAGS[start:end] {
+			target := map[string]interface{}{"__typename": "Commit", "oid": ref.Object.GetSHA()}
+			if ref.Object.GetType() == "tag" {
+				target = map[string]interface{}{"__typename": "Tag", "oid": ref.Object.GetSHA(), "target": map[string]interface{}{"__typename": "Commit", "oid": "deadbeef"}}
+			}
+			nodes = append(nodes, map[string]interface{}{"name": strings.TrimPrefix(ref.GetRef(), "refs/tags/"), "target": target})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+			"repository": map[string]interface{}{"refs": map[string]interface{}{"nodes": nodes, "pageInfo": pageInfo}},
+		}})
+	case strings.Contains(req.Query, "history("):
+		skip := 0
+		for i, commit := range GITHUB_COMMITS {
+			if commit.GetSHA() == req.Variables["oid"] {
+				skip = i
+				break
+			}
+		}
+		commits := GITHUB_COMMITS[skip:]
+		start, end, pageInfo := graphQLPage(len(commits), req.Variables["cursor"])
+		nodes := make([]map[string]interface{}, 0)
+		for _, commit := range commits[start:end] {
+			author := map[string]interface{}{"name": commit.Commit.Author.GetName(), "email": commit.Commit.Author.GetEmail(), "date": commit.Commit.Author.GetDate()}
+			nodes = append(nodes, map[string]interface{}{
+				"oid":                    commit.GetSHA(),
+				"message":                commit.Commit.GetMessage(),
+				"author":                 author,
+				"committer":              author,
+				"parents":                map[string]interface{}{"nodes": []map[string]string{{"oid": commit.Parents[0].GetSHA()}}},
+				"associatedPullRequests": map[string]interface{}{"nodes": []map[string]int{{"number": 42}}},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+			"repository": map[string]interface{}{"object": map[string]interface{}{"history": map[string]interface{}{"nodes": nodes, "pageInfo": pageInfo}}},
+		}})
+	default:
+		http.Error(w, "invalid query", http.StatusBadRequest)
+	}
+}
+
+func getNewGithubGraphQLTestRepo(t *testing.T) (*GitHubRepository, *httptest.Server) {
+	repo := &GitHubRepository{}
+	err := repo.Init(map[string]string{
+		"slug":           "owner/test-repo",
+		"token":          "token",
+		"github_graphql": "true",
+	})
+	require.NoError(t, err)
+	ts := httptest.NewServer(http.HandlerFunc(githubGraphQLHandler))
+	repo.client.BaseURL, _ = url.Parse(ts.URL + "/")
+	return repo, ts
+}
+
+func TestGithubGraphQLURL(t *testing.T) {
+	repo := &GitHubRepository{}
+	require.NoError(t, repo.Init(map[string]string{"slug": "owner/test-repo", "token": "token"}))
+	require.Equal(t, "https://api.github.com/graphql", repo.graphQLURL())
+
+	repo = &GitHubRepository{}
+	require.NoError(t, repo.Init(map[string]string{"slug": "owner/test-repo", "token": "token", "github_enterprise_host": "github.enterprise"}))
+	require.Equal(t, "https://github.enterprise/api/graphql", repo.graphQLURL())
+}
+
+func TestGithubGraphQLGetCommits(t *testing.T) {
+	repo, ts := getNewGithubGraphQLTestRepo(t)
+	defer ts.Close()
+	commits, err := repo.GetCommits("2222", "1111")
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+
+	for i, c := range commits {
+		idxOff := i + 1
+		require.Equal(t, c.SHA, GITHUB_COMMITS[idxOff].GetSHA())
+		require.Equal(t, c.RawMessage, GITHUB_COMMITS[idxOff].Commit.GetMessage())
+		require.Equal(t, "author@example.com", c.Author.Email)
+		require.True(t, commitDate.Equal(c.Committer.Date))
+		require.Equal(t, []string{c.SHA + "-parent"}, c.Parents)
+		require.Equal(t, 42, c.PullRequest)
+	}
+}
+
+func TestGithubGraphQLGetCommitsMergedBeforeRelease(t *testing.T) {
+	releaseDate := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	history := []struct {
+		oid     string
+		message string
+		date    time.Time
+	}{
+		{"merge", "Merge pull request #7 from owner/feature", releaseDate.Add(time.Hour)},
+		// the branch was merged after the release, but committed before it
+		{"feature", "feat: search", releaseDate.Add(-time.Hour)},
+		{"release", "chore: release", releaseDate},
+		{"initial", "Initial commit", releaseDate.Add(-48 * time.Hour)},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+		if strings.Contains(req.Query, "committedDate") {
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{ //nolint:errcheck
+				"repository": map[string]interface{}{"object": map[string]interface{}{"committedDate": releaseDate}},
+			}})
+			return
+		}
+		// GitHub filters by the commit date if the history is limited
+		since, _ := req.Variables["since"].(string)
+		nodes := make([]map[string]interface{}, 0)
+		for _, c := range history {
+			if since != "" && c.date.Format(time.RFC3339) < since {
+				continue
+			}
+			actor := map[string]interface{}{"name": "author", "email": "author@example.com", "date": c.date}
+			nodes = append(nodes, map[string]interface{}{"oid": c.oid, "message": c.message, "author": actor, "committer": actor})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{ //nolint:errcheck
+			"repository": map[string]interface{}{"object": map[string]interface{}{"history": map[string]interface{}{"nodes": nodes}}},
+		}})
+	}))
+	defer ts.Close()
+
+	repo, gts := getNewGithubGraphQLTestRepo(t)
+	gts.Close()
+	repo.client.BaseURL, _ = url.Parse(ts.URL + "/")
+
+	commits, err := repo.GetCommits("release", "merge")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "merge", commits[0].SHA)
+	require.Equal(t, "feature", commits[1].SHA)
+}
+
+func TestGithubGraphQLGetCommitsStopsAtRelease(t *testing.T) {
+	// the history is served in pages of two commits, the release is on the
+	// second page
+	pages := [][]string{{"e", "d"}, {"c", "release"}, {"b", "a"}}
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+		requests++
+		page := 0
+		if cursor, ok := req.Variables["cursor"].(string); ok {
+			page, _ = strconv.Atoi(cursor)
+		}
+		nodes := make([]map[string]interface{}, 0)
+		for _, oid := range pages[page] {
+			actor := map[string]interface{}{"name": "author", "email": "author@example.com", "date": time.Now()}
+			nodes = append(nodes, map[string]interface{}{"oid": oid, "message": "fix: " + oid, "author": actor, "committer": actor})
+		}
+		pageInfo := map[string]interface{}{"hasNextPage": page+1 < len(pages), "endCursor": strconv.Itoa(page + 1)}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{ //nolint:errcheck
+			"repository": map[string]interface{}{"object": map[string]interface{}{"history": map[string]interface{}{"nodes": nodes, "pageInfo": pageInfo}}},
+		}})
+	}))
+	defer ts.Close()
+
+	repo, gts := getNewGithubGraphQLTestRepo(t)
+	gts.Close()
+	repo.client.BaseURL, _ = url.Parse(ts.URL + "/")
+
+	commits, err := repo.GetCommits("release", "e")
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	require.Equal(t, "c", commits[2].SHA)
+	require.Equal(t, 2, requests)
+}
+
+func TestGithubGraphQLGetReleases(t *testing.T) {
+	repo, ts := getNewGithubGraphQLTestRepo(t)
+	defer ts.Close()
+
+	tagFormat, err := semrel.NewTagFormat(semrel.DefaultTagFormat)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		vrange          string
+		re              string
+		expectedSHA     string
+		expectedVersion string
+	}{
+		{"", "", "deadbeef", "2020.4.19"},
+		{"", "^v[0-9]*", "deadbeef", "2.0.0"},
+		{"", tagFormat.Regex(""), "deadbeef", "2.0.0"},
+		{"2-beta", "", "deadbeef", "2.1.0-beta"},
+		{"3-beta", "", "deadbeef", "3.0.0-beta.2"},
+		{"4-beta", "", "deadbeef", "4.0.0-beta"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("VersionRange: %s, RE: %s", tc.vrange, tc.re), func(t *testing.T) {
+			releases, err := repo.GetReleases(tc.re)
+			require.NoError(t, err)
+			release, err := semrel.GetLatestReleaseFromReleases(releases, tc.vrange)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSHA, release.SHA)
+			require.Equal(t, tc.expectedVersion, release.Version)
+		})
+	}
+
+	releases, err := repo.GetReleases("^v1\\.1")
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	require.Equal(t, "deadbeef", releases[0].SHA)
+}
+
+func TestGithubDeleteRelease(t *testing.T) {
+	require := require.New(t)
+	deleted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/repos/owner/test-repo/releases/tags/v2.0.0" {
+			fmt.Fprint(w, `{"id":7}`)
+			return
+		}
+		if r.Method == "DELETE" && (r.URL.Path == "/repos/owner/test-repo/releases/7" || r.URL.Path == "/repos/owner/test-repo/git/refs/tags/v2.0.0") {
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path == "/repos/owner/test-repo/releases/tags/v3.0.0" || r.URL.Path == "/repos/owner/test-repo/git/refs/tags/v3.0.0" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		githubHandler(w, r)
+	}))
+	defer ts.Close()
+	repo := &GitHubRepository{}
+	require.NoError(repo.Init(map[string]string{"slug": "owner/test-repo", "token": "token"}))
+	repo.client.BaseURL, _ = url.Parse(ts.URL + "/")
+
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0"}))
+	require.Equal([]string{"/repos/owner/test-repo/releases/7", "/repos/owner/test-repo/git/refs/tags/v2.0.0"}, deleted)
+
+	// releases that were never created are ignored
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "3.0.0"}))
+}
@@ -0,0 +1,269 @@
+package githubprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/duanqy/semantic-release/pkg/semrel"
+)
+
+const tagsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    refs(refPrefix: "refs/tags/", first: 100, after: $cursor) {
+      nodes {
+        name
+        target {
+          __typename
+          oid
+          ... on Tag {
+            target {
+              __typename
+              oid
+            }
+          }
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+}`
+
+const historyQuery = `query($owner: String!, $name: String!, $oid: GitObjectID!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    object(oid: $oid) {
+      ... on Commit {
+        history(first: 100, after: $cursor) {
+          nodes {
+            oid
+            message
+            author {
+              name
+              email
+              date
+            }
+            committer {
+              name
+              email
+              date
+            }
+            parents(first: 10) {
+              nodes {
+                oid
+              }
+            }
+            associatedPullRequests(first: 1) {
+              nodes {
+                number
+              }
+            }
+          }
+          pageInfo {
+            hasNextPage
+            endCursor
+          }
+        }
+      }
+    }
+  }
+}`
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLObject struct {
+	TypeName string         `json:"__typename"`
+	OID      string         `json:"oid"`
+	Target   *graphQLObject `json:"target"`
+}
+
+type graphQLTags struct {
+	Repository struct {
+		Refs struct {
+			Nodes []struct {
+				Name   string         `json:"name"`
+				Target *graphQLObject `json:"target"`
+			} `json:"nodes"`
+			PageInfo graphQLPageInfo `json:"pageInfo"`
+		} `json:"refs"`
+	} `json:"repository"`
+}
+
+type graphQLGitActor struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+func (a *graphQLGitActor) toSignature() *semrel.Signature {
+	if a == nil {
+		return nil
+	}
+	return &semrel.Signature{Name: a.Name, Email: a.Email, Date: a.Date}
+}
+
+type graphQLHistory struct {
+	Repository struct {
+		Object *struct {
+			History struct {
+				Nodes []struct {
+					OID       string           `json:"oid"`
+					Message   string           `json:"message"`
+					Author    *graphQLGitActor `json:"author"`
+					Committer *graphQLGitActor `json:"committer"`
+					Parents   struct {
+						Nodes []struct {
+							OID string `json:"oid"`
+						} `json:"nodes"`
+					} `json:"parents"`
+					AssociatedPullRequests struct {
+						Nodes []struct {
+							Number int `json:"number"`
+						} `json:"nodes"`
+					} `json:"associatedPullRequests"`
+				} `json:"nodes"`
+				PageInfo graphQLPageInfo `json:"pageInfo"`
+			} `json:"history"`
+		} `json:"object"`
+	} `json:"repository"`
+}
+
+// graphQLURL derives the GraphQL endpoint from the REST base URL, GitHub Enterprise serves it at /api/graphql.
+func (repo *GitHubRepository) graphQLURL() string {
+	u := *repo.client.BaseURL
+	u.Path = strings.TrimSuffix(u.Path, "v3/") + "graphql"
+	return u.String()
+}
+
+func (repo *GitHubRepository) graphQL(query string, variables map[string]interface{}, out interface{}) error {
+	variables["owner"] = repo.owner
+	variables["name"] = repo.repo
+	body, err := json.Marshal(&graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, repo.graphQLURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := repo.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("github graphql: %d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	res := &graphQLResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return err
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("github graphql: %s", res.Errors[0].Message)
+	}
+	return json.Unmarshal(res.Data, out)
+}
+
+func (repo *GitHubRepository) getCommitsGraphQL(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	allCommits := make([]*semrel.RawCommit, 0)
+	variables := map[string]interface{}{"oid": toSha}
+	// the history is paged until the previous release, a date filter would
+	// miss merged commits that are older than the release
+	for {
+		res := &graphQLHistory{}
+		if err := repo.graphQL(historyQuery, variables, res); err != nil {
+			return nil, err
+		}
+		if res.Repository.Object == nil {
+			return nil, fmt.Errorf("commit %s not found", toSha)
+		}
+		history := res.Repository.Object.History
+		for _, commit := range history.Nodes {
+			if commit.OID == fromSha {
+				return allCommits, nil
+			}
+			parents := make([]string, len(commit.Parents.Nodes))
+			for i, p := range commit.Parents.Nodes {
+				parents[i] = p.OID
+			}
+			pullRequest := 0
+			if len(commit.AssociatedPullRequests.Nodes) > 0 {
+				pullRequest = commit.AssociatedPullRequests.Nodes[0].Number
+			}
+			allCommits = append(allCommits, &semrel.RawCommit{
+				SHA:         commit.OID,
+				RawMessage:  commit.Message,
+				Author:      commit.Author.toSignature(),
+				Committer:   commit.Committer.toSignature(),
+				Parents:     parents,
+				PullRequest: pullRequest,
+			})
+		}
+		if !history.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = history.PageInfo.EndCursor
+	}
+	return allCommits, nil
+}
+
+func (repo *GitHubRepository) getReleasesGraphQL(rawRe string) ([]*semrel.Release, error) {
+	re := regexp.MustCompile(rawRe)
+	allReleases := make([]*semrel.Release, 0)
+	variables := map[string]interface{}{}
+	for {
+		res := &graphQLTags{}
+		if err := repo.graphQL(tagsQuery, variables, res); err != nil {
+			return nil, err
+		}
+		refs := res.Repository.Refs
+		for _, ref := range refs.Nodes {
+			if rawRe != "" && !re.MatchString(ref.Name) {
+				continue
+			}
+			target := ref.Target
+			// annotated tags are peeled in the same request
+			if target != nil && target.TypeName == "Tag" {
+				target = target.Target
+			}
+			if target == nil || target.TypeName != "Commit" {
+				continue
+			}
+			version, err := semrel.ParseTagVersion(repo.versionScheme, re, ref.Name)
+			if err != nil {
+				continue
+			}
+			allReleases = append(allReleases, &semrel.Release{SHA: target.OID, Version: version})
+		}
+		if !refs.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = refs.PageInfo.EndCursor
+	}
+	return allReleases, nil
+}
@@ -0,0 +1,125 @@
+package gitlabprovider
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/xanzy/go-gitlab"
+)
+
+var invalidPackageNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// releasePackageName returns the name of the generic package of a release. The
+// tags of a monorepo package like "api/v1.0.0" get their own package
+// "<name>-api", tags like "v1.0.0" use the configured name.
+func (repo *GitLabRepository) releasePackageName(release *plugin.CreateReleaseConfig) string {
+	parts := []string{release.TagName()}
+	if i := strings.Index(parts[0], release.NewVersion); i >= 0 {
+		parts = []string{strings.TrimSuffix(parts[0][:i], "v"), parts[0][i+len(release.NewVersion):]}
+	}
+	name := []string{repo.packageName}
+	for _, part := range parts {
+		if part = strings.Trim(invalidPackageNameChars.ReplaceAllString(part, "-"), "-._"); part != "" {
+			name = append(name, part)
+		}
+	}
+	return strings.Join(name, "-")
+}
+
+// packageFilePath returns the API path of a file in the generic package registry of the project.
+func (repo *GitLabRepository) packageFilePath(name, version, file string) string {
+	return fmt.Sprintf("projects/%s/packages/generic/%s/%s/%s",
+		url.PathEscape(repo.projectID),
+		url.PathEscape(name),
+		url.PathEscape(version),
+		url.PathEscape(filepath.Base(file)))
+}
+
+// uploadPackageFile publishes the file to the generic package registry and returns its download URL.
+func (repo *GitLabRepository) uploadPackageFile(name, version, file string) (string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	path := repo.packageFilePath(name, version, file)
+	req, err := repo.client.NewRequest(http.MethodPut, path, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := req.SetBody(data); err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if _, err := repo.client.Do(req, nil); err != nil {
+		return "", fmt.Errorf("upload package file %s: %w", file, err)
+	}
+	return repo.client.BaseURL().String() + path, nil
+}
+
+type packageFile struct {
+	name string
+	url  string
+}
+
+// uploadPackageFiles publishes all assets to the generic package registry.
+func (repo *GitLabRepository) uploadPackageFiles(name, version string, files []string) ([]*packageFile, error) {
+	packageFiles := make([]*packageFile, 0, len(files))
+	for _, file := range files {
+		link, err := repo.uploadPackageFile(name, version, file)
+		if err != nil {
+			return nil, err
+		}
+		packageFiles = append(packageFiles, &packageFile{name: filepath.Base(file), url: link})
+	}
+	return packageFiles, nil
+}
+
+// createReleaseLinks attaches the uploaded package files to the release of the tag.
+func (repo *GitLabRepository) createReleaseLinks(tag string, packageFiles []*packageFile) error {
+	for _, pf := range packageFiles {
+		_, _, err := repo.client.ReleaseLinks.CreateReleaseLink(repo.projectID, tag, &gitlab.CreateReleaseLinkOptions{
+			Name:     gitlab.String(pf.name),
+			URL:      gitlab.String(pf.url),
+			FilePath: gitlab.String("/" + pf.name),
+			LinkType: gitlab.LinkType(repo.assetLinkType),
+		})
+		if err != nil {
+			return fmt.Errorf("create release link %s: %w", pf.name, err)
+		}
+	}
+	return nil
+}
+
+// deletePackage removes the generic package of the version and its files.
+func (repo *GitLabRepository) deletePackage(name, version string) error {
+	opts := &gitlab.ListProjectPackagesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		PackageType: gitlab.String("generic"),
+		PackageName: gitlab.String(name),
+	}
+	for {
+		packages, resp, err := repo.client.Packages.ListProjectPackages(repo.projectID, opts)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range packages {
+			if pkg.Name != name || pkg.Version != version {
+				continue
+			}
+			resp, err := repo.client.Packages.DeleteProjectPackage(repo.projectID, pkg.ID)
+			if err != nil && !isNotFound(resp) {
+				return fmt.Errorf("delete package %s %s: %w", name, version, err)
+			}
+		}
+		if resp.CurrentPage >= resp.TotalPages {
+			return nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -3,10 +3,11 @@ package gitlabprovider
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"regexp"
+	"time"
 
-	"github.com/Masterminds/semver/v3"
 	"github.com/duanqy/semantic-release/pkg/plugin"
 	"github.com/duanqy/semantic-release/pkg/semrel"
 	"github.com/xanzy/go-gitlab"
@@ -19,9 +20,14 @@ func init() {
 var PVERSION = "dev"
 
 type GitLabRepository struct {
-	projectID string
-	branch    string
-	client    *gitlab.Client
+	projectID     string
+	branch        string
+	packageName   string
+	assetLinkType gitlab.LinkTypeValue
+	commitFiles   bool
+	pullRequests  bool
+	versionScheme semrel.VersionScheme
+	client        *gitlab.Client
 }
 
 func (repo *GitLabRepository) Init(config map[string]string) error {
@@ -51,13 +57,36 @@ func (repo *GitLabRepository) Init(config map[string]string) error {
 		return fmt.Errorf("gitlab_projectid is required")
 	}
 
+	packageName := config["gitlab_package_name"]
+	if packageName == "" {
+		packageName = os.Getenv("CI_PROJECT_NAME")
+	}
+	if packageName == "" {
+		packageName = "release"
+	}
+
+	assetLinkType := gitlab.LinkTypeValue(config["gitlab_asset_link_type"])
+	switch assetLinkType {
+	case "":
+		assetLinkType = gitlab.PackageLinkType
+	case gitlab.OtherLinkType, gitlab.PackageLinkType, gitlab.ImageLinkType, gitlab.RunbookLinkType:
+	default:
+		return fmt.Errorf("invalid gitlab_asset_link_type: %s", assetLinkType)
+	}
+
 	repo.projectID = projectID
 	repo.branch = branch
+	repo.packageName = packageName
+	repo.assetLinkType = assetLinkType
+	repo.commitFiles = config["commit_files"] == "true"
+	repo.pullRequests = config["pull_requests"] == "true"
+	versionScheme, err := semrel.NewVersionScheme(config["version_scheme"], config["calver_format"])
+	if err != nil {
+		return err
+	}
+	repo.versionScheme = versionScheme
 
-	var (
-		client *gitlab.Client
-		err    error
-	)
+	var client *gitlab.Client
 
 	if gitlabBaseUrl != "" {
 		client, err = gitlab.NewClient(token, gitlab.WithBaseURL(gitlabBaseUrl))
@@ -107,10 +136,24 @@ func (repo *GitLabRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCo
 		}
 
 		for _, commit := range commits {
-			allCommits = append(allCommits, &semrel.RawCommit{
+			rawCommit := &semrel.RawCommit{
 				SHA:        commit.ID,
 				RawMessage: commit.Message,
-			})
+				Author:     toSignature(commit.AuthorName, commit.AuthorEmail, commit.AuthoredDate),
+				Committer:  toSignature(commit.CommitterName, commit.CommitterEmail, commit.CommittedDate),
+				Parents:    commit.ParentIDs,
+			}
+			if repo.commitFiles {
+				if rawCommit.Files, err = repo.getCommitFiles(commit.ID); err != nil {
+					return nil, err
+				}
+			}
+			if repo.pullRequests {
+				if rawCommit.PullRequest, err = repo.getMergeRequest(commit.ID); err != nil {
+					return nil, err
+				}
+			}
+			allCommits = append(allCommits, rawCommit)
 		}
 
 		// We cannot always rely on the total pages header
@@ -126,6 +169,48 @@ func (repo *GitLabRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCo
 	return allCommits, nil
 }
 
+func (repo *GitLabRepository) getCommitFiles(sha string) ([]string, error) {
+	opts := &gitlab.GetCommitDiffOptions{Page: 1, PerPage: 100}
+	files := make([]string, 0)
+	for {
+		diffs, resp, err := repo.client.Commits.GetCommitDiff(repo.projectID, sha, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, diff := range diffs {
+			files = append(files, diff.NewPath)
+			if diff.RenamedFile {
+				files = append(files, diff.OldPath)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return files, nil
+}
+
+// getMergeRequest returns the number of the merge request of a commit or 0.
+func (repo *GitLabRepository) getMergeRequest(sha string) (int, error) {
+	mrs, _, err := repo.client.Commits.GetMergeRequestsByCommit(repo.projectID, sha)
+	if err != nil {
+		return 0, err
+	}
+	if len(mrs) == 0 {
+		return 0, nil
+	}
+	return mrs[0].IID, nil
+}
+
+func toSignature(name, email string, date *time.Time) *semrel.Signature {
+	sig := &semrel.Signature{Name: name, Email: email}
+	if date != nil {
+		sig.Date = *date
+	}
+	return sig
+}
+
 func (repo *GitLabRepository) GetReleases(rawRe string) ([]*semrel.Release, error) {
 	re := regexp.MustCompile(rawRe)
 	allReleases := make([]*semrel.Release, 0)
@@ -148,14 +233,14 @@ func (repo *GitLabRepository) GetReleases(rawRe string) ([]*semrel.Release, erro
 				continue
 			}
 
-			version, err := semver.NewVersion(tag.Name)
+			version, err := semrel.ParseTagVersion(repo.versionScheme, re, tag.Name)
 			if err != nil {
 				continue
 			}
 
 			allReleases = append(allReleases, &semrel.Release{
 				SHA:     tag.Commit.ID,
-				Version: version.String(),
+				Version: version,
 			})
 		}
 
@@ -170,8 +255,39 @@ func (repo *GitLabRepository) GetReleases(rawRe string) ([]*semrel.Release, erro
 }
 
 func (repo *GitLabRepository) CreateRelease(release *plugin.CreateReleaseConfig) error {
-	tag := fmt.Sprintf("v%s", release.NewVersion)
+	tag := release.TagName()
+
+	// publish the assets first to not create a release with missing files
+	assets, err := plugin.ExpandAssets(release.Assets)
+	if err != nil {
+		return err
+	}
+	if err := plugin.CheckAssetNames(assets); err != nil {
+		return err
+	}
+	if len(assets) == 0 {
+		return repo.createRelease(release)
+	}
+	packageName := repo.releasePackageName(release)
+	packageFiles, err := repo.uploadPackageFiles(packageName, release.NewVersion, assets)
+	if err == nil {
+		err = repo.createRelease(release)
+	}
+	if err == nil {
+		err = repo.createReleaseLinks(tag, packageFiles)
+	}
+	if err != nil {
+		// the files of a failed release are not used by any release
+		if deleteErr := repo.deletePackage(packageName, release.NewVersion); deleteErr != nil {
+			return fmt.Errorf("%w (deleting the package files failed: %v)", err, deleteErr)
+		}
+		return err
+	}
+	return nil
+}
 
+func (repo *GitLabRepository) createRelease(release *plugin.CreateReleaseConfig) error {
+	tag := release.TagName()
 	// Gitlab does not have any notion of pre-releases
 	_, _, err := repo.client.Releases.CreateRelease(repo.projectID, &gitlab.CreateReleaseOptions{
 		TagName: &tag,
@@ -179,10 +295,29 @@ func (repo *GitLabRepository) CreateRelease(release *plugin.CreateReleaseConfig)
 		// TODO: this may been to be wrapped in ```
 		Description: &release.Changelog,
 	})
-
 	return err
 }
 
+func (repo *GitLabRepository) DeleteRelease(release *plugin.CreateReleaseConfig) error {
+	tag := release.TagName()
+	_, resp, err := repo.client.Releases.DeleteRelease(repo.projectID, tag)
+	if err != nil && !isNotFound(resp) {
+		return err
+	}
+	resp, err = repo.client.Tags.DeleteTag(repo.projectID, tag)
+	if err != nil && !isNotFound(resp) {
+		return err
+	}
+	if len(release.Assets) > 0 {
+		return repo.deletePackage(repo.releasePackageName(release), release.NewVersion)
+	}
+	return nil
+}
+
+func isNotFound(resp *gitlab.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
+}
+
 func (repo *GitLabRepository) Name() string {
 	return "gitlab"
 }
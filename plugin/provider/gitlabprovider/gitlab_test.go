@@ -3,10 +3,15 @@ package gitlabprovider
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/duanqy/semantic-release/pkg/plugin"
 	"github.com/duanqy/semantic-release/pkg/semrel"
@@ -40,7 +45,18 @@ func TestNewGitlabRepository(t *testing.T) {
 }
 
 func createGitlabCommit(sha, message string) *gitlab.Commit {
-	return &gitlab.Commit{ID: sha, Message: message}
+	date := time.Date(2020, 4, 19, 12, 0, 0, 0, time.UTC)
+	return &gitlab.Commit{
+		ID:             sha,
+		Message:        message,
+		AuthorName:     "author",
+		AuthorEmail:    "author@example.com",
+		AuthoredDate:   &date,
+		CommitterName:  "committer",
+		CommitterEmail: "committer@example.com",
+		CommittedDate:  &date,
+		ParentIDs:      []string{sha + "-parent"},
+	}
 }
 
 func createGitlabTag(name, sha string) *gitlab.Tag {
@@ -93,6 +109,22 @@ func GitlabHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	commitsPath := fmt.Sprintf("/api/v4/projects/%d/repository/commits/", GITLAB_PROJECT_ID)
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, commitsPath) && strings.HasSuffix(r.URL.Path, "/diff") {
+		sha := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, commitsPath), "/diff")
+		json.NewEncoder(w).Encode([]*gitlab.Diff{{NewPath: sha + "/main.go", OldPath: sha + "/main.go"}})
+		return
+	}
+
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, commitsPath) && strings.HasSuffix(r.URL.Path, "/merge_requests") {
+		mrs := []*gitlab.MergeRequest{}
+		if sha := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, commitsPath), "/merge_requests"); sha != GITLAB_COMMITS[0].ID {
+			mrs = append(mrs, &gitlab.MergeRequest{ID: 1234, IID: 7})
+		}
+		json.NewEncoder(w).Encode(mrs)
+		return
+	}
+
 	if r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID) {
 		json.NewEncoder(w).Encode(GITLAB_TAGS)
 		return
@@ -146,6 +178,35 @@ func TestGitlabGetCommits(t *testing.T) {
 	for i, c := range commits {
 		require.Equal(t, c.SHA, GITLAB_COMMITS[i].ID)
 		require.Equal(t, c.RawMessage, GITLAB_COMMITS[i].Message)
+		require.Equal(t, "author", c.Author.Name)
+		require.Equal(t, "committer@example.com", c.Committer.Email)
+		require.True(t, GITLAB_COMMITS[i].CommittedDate.Equal(c.Committer.Date))
+		require.Equal(t, GITLAB_COMMITS[i].ParentIDs, c.Parents)
+	}
+}
+
+func TestGitlabGetCommitsWithFiles(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.commitFiles = true
+	commits, err := repo.GetCommits("", "")
+	require.NoError(t, err)
+	require.Len(t, commits, 4)
+	for _, c := range commits {
+		require.Equal(t, []string{c.SHA + "/main.go"}, c.Files)
+	}
+}
+
+func TestGitlabGetCommitsWithMergeRequests(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.pullRequests = true
+	commits, err := repo.GetCommits("", "")
+	require.NoError(t, err)
+	require.Len(t, commits, 4)
+	require.Zero(t, commits[0].PullRequest)
+	for _, c := range commits[1:] {
+		require.Equal(t, 7, c.PullRequest)
 	}
 }
 
@@ -153,6 +214,9 @@ func TestGitlabGetReleases(t *testing.T) {
 	repo, ts := getNewGitlabTestRepo(t)
 	defer ts.Close()
 
+	tagFormat, err := semrel.NewTagFormat(semrel.DefaultTagFormat)
+	require.NoError(t, err)
+
 	testCases := []struct {
 		vrange          string
 		re              string
@@ -161,6 +225,7 @@ func TestGitlabGetReleases(t *testing.T) {
 	}{
 		{"", "", "deadbeef", "2020.4.19"},
 		{"", "^v[0-9]*", "deadbeef", "2.0.0"},
+		{"", tagFormat.Regex(""), "deadbeef", "2.0.0"},
 		{"2-beta", "", "deadbeef", "2.1.0-beta"},
 		{"3-beta", "", "deadbeef", "3.0.0-beta.2"},
 		{"4-beta", "", "deadbeef", "4.0.0-beta"},
@@ -184,3 +249,193 @@ func TestGitlabCreateRelease(t *testing.T) {
 	err := repo.CreateRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
 	require.NoError(t, err)
 }
+
+func TestGitlabCreateReleaseWithAssets(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "gitlab-assets")
+	require.NoError(err)
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "app.tar.gz"), []byte("archive"), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "runbook.md"), []byte("runbook"), 0644))
+
+	uploads := map[string]string{}
+	links := []map[string]string{}
+	packagePath := fmt.Sprintf("/api/v4/projects/%d/packages/generic/my-app/2.0.0/", GITLAB_PROJECT_ID)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" && strings.HasPrefix(r.URL.Path, packagePath) {
+			data, _ := ioutil.ReadAll(r.Body)
+			uploads[strings.TrimPrefix(r.URL.Path, packagePath)] = string(data)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, "{}")
+			return
+		}
+		if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases/v2.0.0/assets/links", GITLAB_PROJECT_ID) {
+			var data map[string]string
+			json.NewDecoder(r.Body).Decode(&data) //nolint:errcheck
+			links = append(links, data)
+			fmt.Fprint(w, "{}")
+			return
+		}
+		// the release must not be created before all files are uploaded
+		if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID) && len(uploads) != 2 {
+			http.Error(w, "missing uploads", http.StatusBadRequest)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err = repo.Init(map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "token",
+		"gitlab_projectid":       strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_package_name":    "my-app",
+		"gitlab_asset_link_type": "runbook",
+	})
+	require.NoError(err)
+
+	err = repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "2.0.0",
+		SHA:        "deadbeef",
+		Assets:     []string{filepath.Join(dir, "*")},
+	})
+	require.NoError(err)
+	require.Equal(map[string]string{"app.tar.gz": "archive", "runbook.md": "runbook"}, uploads)
+	require.Len(links, 2)
+	require.Equal("app.tar.gz", links[0]["name"])
+	require.Equal(ts.URL+packagePath+"app.tar.gz", links[0]["url"])
+	require.Equal("/app.tar.gz", links[0]["filepath"])
+	require.Equal("runbook", links[0]["link_type"])
+
+	// files with the same name would overwrite each other in the package
+	uploads = map[string]string{}
+	for _, platform := range []string{"darwin", "linux"} {
+		require.NoError(os.Mkdir(filepath.Join(dir, platform), 0755))
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, platform, "app"), []byte(platform), 0644))
+	}
+	err = repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "2.0.0",
+		SHA:        "deadbeef",
+		Assets:     []string{filepath.Join(dir, "*", "app")},
+	})
+	require.EqualError(err, fmt.Sprintf("assets %s and %s have the same name app", filepath.Join(dir, "darwin", "app"), filepath.Join(dir, "linux", "app")))
+	require.Empty(uploads)
+
+	repo = &GitLabRepository{}
+	err = repo.Init(map[string]string{
+		"token":                  "token",
+		"gitlab_projectid":       "1",
+		"gitlab_asset_link_type": "binary",
+	})
+	require.EqualError(err, "invalid gitlab_asset_link_type: binary")
+}
+
+func TestGitlabDeleteRelease(t *testing.T) {
+	require := require.New(t)
+	deleted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL.Path)
+			if strings.HasSuffix(r.URL.Path, "/releases/v2.0.0") {
+				// the tag was created, but not the release
+				http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+	})
+	require.NoError(err)
+
+	require.NoError(repo.DeleteRelease(&plugin.CreateReleaseConfig{NewVersion: "2.0.0"}))
+	require.Equal([]string{
+		fmt.Sprintf("/api/v4/projects/%d/releases/v2.0.0", GITLAB_PROJECT_ID),
+		fmt.Sprintf("/api/v4/projects/%d/repository/tags/v2.0.0", GITLAB_PROJECT_ID),
+	}, deleted)
+}
+
+func TestGitlabReleasePackageName(t *testing.T) {
+	repo := &GitLabRepository{packageName: "my-app"}
+	testCases := []struct {
+		tag     string
+		version string
+		name    string
+	}{
+		{"", "2.0.0", "my-app"},
+		{"v2.0.0", "2.0.0", "my-app"},
+		{"api/v2.0.0", "2.0.0", "my-app-api"},
+		{"web@2.0.0", "2.0.0", "my-app-web"},
+		{"release-2.0.0-final", "2.0.0", "my-app-release-final"},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.name, repo.releasePackageName(&plugin.CreateReleaseConfig{Tag: tc.tag, NewVersion: tc.version}), tc.tag)
+	}
+}
+
+func TestGitlabDeleteReleasePackage(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "gitlab-assets")
+	require.NoError(err)
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "app.tar.gz"), []byte("archive"), 0644))
+
+	uploads := []string{}
+	deleted := []string{}
+	projectPath := fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, projectPath+"/packages/generic/"):
+			uploads = append(uploads, strings.TrimPrefix(r.URL.Path, projectPath+"/packages/generic/"))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && r.URL.Path == projectPath+"/releases":
+			http.Error(w, `{"message":"release failed"}`, http.StatusUnprocessableEntity)
+		case r.Method == "GET" && r.URL.Path == projectPath+"/packages":
+			if r.URL.Query().Get("package_type") != "generic" || r.URL.Query().Get("package_name") != "my-app-api" {
+				http.Error(w, "invalid filter", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `[{"id":6,"name":"my-app-api","version":"1.0.0"},{"id":7,"name":"my-app-api","version":"2.0.0"}]`)
+		case r.Method == "DELETE":
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, projectPath))
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err = repo.Init(map[string]string{
+		"gitlab_baseurl":      ts.URL,
+		"token":               "token",
+		"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_package_name": "my-app",
+	})
+	require.NoError(err)
+	release := &plugin.CreateReleaseConfig{
+		NewVersion: "2.0.0",
+		Tag:        "api-v2.0.0",
+		SHA:        "deadbeef",
+		Assets:     []string{filepath.Join(dir, "app.tar.gz")},
+	}
+
+	// the files of a failed release are removed
+	require.Error(repo.CreateRelease(release))
+	require.Equal([]string{"my-app-api/2.0.0/app.tar.gz"}, uploads)
+	require.Equal([]string{"/packages/7"}, deleted)
+
+	// and the files of a release that is rolled back
+	deleted = []string{}
+	require.NoError(repo.DeleteRelease(release))
+	require.Equal([]string{"/releases/api-v2.0.0", "/repository/tags/api-v2.0.0", "/packages/7"}, deleted)
+}
@@ -1,13 +1,17 @@
 package gitprovider
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 
-	"github.com/Masterminds/semver/v3"
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/duanqy/semantic-release/pkg/plugin"
 	"github.com/duanqy/semantic-release/pkg/semrel"
 	"github.com/go-git/go-git/v5"
@@ -29,18 +33,26 @@ var PVERSION = "dev"
 
 type Repository struct {
 	defaultBranch string
+	owner         string
+	name          string
 	taggerName    string
 	taggerEmail   string
 	remoteName    string
+	commitFiles   bool
+	versionScheme semrel.VersionScheme
 	auth          transport.AuthMethod
 	repo          *git.Repository
+
+	signKey   *openpgp.Entity
+	sshSigner cryptossh.Signer
+
+	tagVerification string
+	verifyKeyRing   string
+	allowedSigners  []cryptossh.PublicKey
 }
 
 func (repo *Repository) Init(config map[string]string) error {
 	repo.defaultBranch = config["default_branch"]
-	if repo.defaultBranch == "" {
-		repo.defaultBranch = "master"
-	}
 
 	repo.taggerName = config["tagger_name"]
 	if repo.taggerName == "" {
@@ -53,6 +65,16 @@ func (repo *Repository) Init(config map[string]string) error {
 	}
 
 	repo.remoteName = config["remote_name"]
+	if repo.remoteName == "" {
+		repo.remoteName = git.DefaultRemoteName
+	}
+
+	repo.commitFiles = config["commit_files"] == "true"
+	versionScheme, err := semrel.NewVersionScheme(config["version_scheme"], config["calver_format"])
+	if err != nil {
+		return err
+	}
+	repo.versionScheme = versionScheme
 
 	if config["auth_username"] == "" {
 		config["auth_username"] = "git"
@@ -77,20 +99,122 @@ func (repo *Repository) Init(config map[string]string) error {
 		repo.auth = nil
 	}
 
-	gr, err := git.PlainOpen(".")
+	if config["sign_gpg_key"] != "" && config["sign_ssh_key"] != "" {
+		return errors.New("sign_gpg_key and sign_ssh_key are mutually exclusive")
+	}
+	if config["sign_gpg_key"] != "" {
+		signKey, err := loadOpenPGPKey(config["sign_gpg_key"], config["sign_gpg_passphrase"])
+		if err != nil {
+			return fmt.Errorf("loadOpenPGPKey: %w", err)
+		}
+		repo.signKey = signKey
+	}
+	if config["sign_ssh_key"] != "" {
+		signer, err := loadSSHSigner(config["sign_ssh_key"], config["sign_ssh_passphrase"])
+		if err != nil {
+			return fmt.Errorf("loadSSHSigner: %w", err)
+		}
+		repo.sshSigner = signer
+	}
+
+	repo.tagVerification = config["verify_tags"]
+	switch repo.tagVerification {
+	case "", tagVerificationIgnore, tagVerificationReject:
+	default:
+		return fmt.Errorf("invalid verify_tags value: %s", repo.tagVerification)
+	}
+	if config["verify_gpg_keyring"] != "" {
+		keyRing, err := ioutil.ReadFile(config["verify_gpg_keyring"])
+		if err != nil {
+			return fmt.Errorf("read verify_gpg_keyring: %w", err)
+		}
+		repo.verifyKeyRing = string(keyRing)
+	}
+	if config["verify_ssh_allowed_signers"] != "" {
+		data, err := ioutil.ReadFile(config["verify_ssh_allowed_signers"])
+		if err != nil {
+			return fmt.Errorf("read verify_ssh_allowed_signers: %w", err)
+		}
+		if repo.allowedSigners, err = parseAllowedSigners(data); err != nil {
+			return fmt.Errorf("parseAllowedSigners: %w", err)
+		}
+	}
+	if repo.tagVerification != "" && repo.verifyKeyRing == "" && len(repo.allowedSigners) == 0 {
+		return errors.New("verify_tags requires verify_gpg_keyring or verify_ssh_allowed_signers")
+	}
+
+	gitPath := config["git_path"]
+	if gitPath == "" {
+		gitPath = "."
+	}
+	gr, err := git.PlainOpen(gitPath)
 	if err != nil {
 		return fmt.Errorf("git.PlainOpen: %w", err)
 	}
 	repo.repo = gr
 
+	remote, err := gr.Remote(repo.remoteName)
+	if err != nil && !errors.Is(err, git.ErrRemoteNotFound) {
+		return fmt.Errorf("git.Remote: %w", err)
+	}
+	if remote != nil && len(remote.Config().URLs) > 0 {
+		repo.owner, repo.name = parseRemoteURL(remote.Config().URLs[0])
+	}
+
 	return nil
 }
 
+var scpLikeURLPattern = regexp.MustCompile(`^(?:[^@/]+@)?[^:/]+:(.*)$`)
+
+// parseRemoteURL extracts the owner and the repository name from a remote URL.
+// Supported are URLs with a scheme (https, ssh, git, file), scp-like URLs
+// (git@host:owner/repo.git) and plain paths. Everything in front of the last
+// path segment is treated as owner, so nested groups are preserved.
+func parseRemoteURL(rawURL string) (string, string) {
+	repoPath := rawURL
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", ""
+		}
+		repoPath = u.Path
+		if u.Scheme == "file" {
+			repoPath = path.Join(path.Base(path.Dir(repoPath)), path.Base(repoPath))
+		}
+	} else if found := scpLikeURLPattern.FindStringSubmatch(rawURL); found != nil {
+		repoPath = found[1]
+	} else {
+		repoPath = path.Join(path.Base(path.Dir(repoPath)), path.Base(repoPath))
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	idx := strings.LastIndex(repoPath, "/")
+	if idx < 0 {
+		return "", repoPath
+	}
+	return repoPath[:idx], repoPath[idx+1:]
+}
+
+// resolveDefaultBranch returns the branch the remote HEAD points to, falling back to
+// master. The checked out branch is not used, as it is usually the branch that
+// is being built.
+func (repo *Repository) resolveDefaultBranch() string {
+	remoteHead := plumbing.NewRemoteHEADReferenceName(repo.remoteName)
+	if ref, err := repo.repo.Storer.Reference(remoteHead); err == nil && ref.Type() == plumbing.SymbolicReference {
+		return strings.TrimPrefix(ref.Target().String(), fmt.Sprintf("refs/remotes/%s/", repo.remoteName))
+	}
+	return "master"
+}
+
 func (repo *Repository) GetInfo() (*plugin.RepositoryInfo, error) {
+	defaultBranch := repo.defaultBranch
+	if defaultBranch == "" {
+		defaultBranch = repo.resolveDefaultBranch()
+	}
 	return &plugin.RepositoryInfo{
-		Owner:         "",
-		Repo:          "",
-		DefaultBranch: repo.defaultBranch,
+		Owner:         repo.owner,
+		Repo:          repo.name,
+		DefaultBranch: defaultBranch,
 		Private:       false,
 	}, nil
 }
@@ -113,10 +237,25 @@ func (repo *Repository) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit,
 		if commit.Hash.String() == fromSha {
 			return storer.ErrStop
 		}
-		allCommits = append(allCommits, &semrel.RawCommit{
+		parents := make([]string, len(commit.ParentHashes))
+		for i, p := range commit.ParentHashes {
+			parents[i] = p.String()
+		}
+		rawCommit := &semrel.RawCommit{
 			SHA:        commit.Hash.String(),
 			RawMessage: commit.Message,
-		})
+			Author:     &semrel.Signature{Name: commit.Author.Name, Email: commit.Author.Email, Date: commit.Author.When},
+			Committer:  &semrel.Signature{Name: commit.Committer.Name, Email: commit.Committer.Email, Date: commit.Committer.When},
+			Parents:    parents,
+		}
+		if repo.commitFiles {
+			files, err := changedFiles(commit)
+			if err != nil {
+				return fmt.Errorf("changedFiles %s: %w", commit.Hash, err)
+			}
+			rawCommit.Files = files
+		}
+		allCommits = append(allCommits, rawCommit)
 		return nil
 	})
 	if err != nil {
@@ -126,6 +265,37 @@ func (repo *Repository) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit,
 	return allCommits, nil
 }
 
+// changedFiles returns the files a commit changed compared to its first parent.
+func changedFiles(commit *object.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	parentTree := &object.Tree{}
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
 func (repo *Repository) GetReleases(rawRe string) ([]*semrel.Release, error) {
 	re := regexp.MustCompile(rawRe)
 	allReleases := make([]*semrel.Release, 0)
@@ -143,22 +313,32 @@ func (repo *Repository) GetReleases(rawRe string) ([]*semrel.Release, error) {
 		if rawRe != "" && !re.MatchString(tag) {
 			return nil
 		}
-		version, err := semver.NewVersion(tag)
+		version, err := semrel.ParseTagVersion(repo.versionScheme, re, tag)
 		if err != nil {
 			return nil
 		}
 
 		// resolve annotated tags
 		sha := reference.Hash()
-		if tagObj, err := repo.repo.TagObject(sha); err == nil {
+		tagObj, err := repo.repo.TagObject(sha)
+		if err == nil {
 			if com, err := tagObj.Commit(); err == nil {
 				sha = com.Hash
 			}
 		}
 
+		if repo.tagVerification != "" {
+			if err := repo.verifyTag(tagObj); err != nil {
+				if repo.tagVerification == tagVerificationReject {
+					return fmt.Errorf("verify tag %s: %w", tag, err)
+				}
+				return nil
+			}
+		}
+
 		allReleases = append(allReleases, &semrel.Release{
 			SHA:     sha.String(),
-			Version: version.String(),
+			Version: version,
 		})
 		return nil
 	})
@@ -170,26 +350,57 @@ func (repo *Repository) GetReleases(rawRe string) ([]*semrel.Release, error) {
 }
 
 func (repo *Repository) CreateRelease(release *plugin.CreateReleaseConfig) error {
-	tag := fmt.Sprintf("v%s", release.NewVersion)
-	_, err := repo.repo.CreateTag(tag, plumbing.NewHash(release.SHA), &git.CreateTagOptions{
-		Message: release.Changelog,
-		Tagger: &object.Signature{
-			Name:  repo.taggerName,
-			Email: repo.taggerEmail,
-			When:  time.Now(),
+	tag := release.TagName()
+	tagger := &object.Signature{
+		Name:  repo.taggerName,
+		Email: repo.taggerEmail,
+		When:  time.Now(),
+	}
+	if repo.sshSigner != nil {
+		if err := repo.createSSHSignedTag(tag, plumbing.NewHash(release.SHA), release.Changelog, tagger); err != nil {
+			return fmt.Errorf("createSSHSignedTag: %w", err)
+		}
+	} else {
+		_, err := repo.repo.CreateTag(tag, plumbing.NewHash(release.SHA), &git.CreateTagOptions{
+			Message: release.Changelog,
+			Tagger:  tagger,
+			SignKey: repo.signKey,
+		})
+		if err != nil {
+			return fmt.Errorf("git.CreateTag: %w", err)
+		}
+	}
+	err := repo.repo.Push(&git.PushOptions{
+		RemoteName: repo.remoteName,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag)),
 		},
+		Auth: repo.auth,
 	})
-	if err != nil {
-		return fmt.Errorf("git.CreateTag: %w", err)
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		// do not leave a tag behind that only exists locally
+		_ = repo.repo.DeleteTag(tag)
+		return fmt.Errorf("repo.Push tag: %w", err)
+	}
+	return nil
+}
+
+func (repo *Repository) DeleteRelease(release *plugin.CreateReleaseConfig) error {
+	tag := release.TagName()
+	if err := repo.repo.DeleteTag(tag); err != nil && !errors.Is(err, git.ErrTagNotFound) {
+		return fmt.Errorf("git.DeleteTag: %w", err)
 	}
-	err = repo.repo.Push(&git.PushOptions{
+	err := repo.repo.Push(&git.PushOptions{
 		RemoteName: repo.remoteName,
 		RefSpecs: []config.RefSpec{
-			config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag)),
+			config.RefSpec(fmt.Sprintf(":refs/tags/%s", tag)),
 		},
 		Auth: repo.auth,
 	})
-	return fmt.Errorf("repo.Push tag: %w", err)
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("repo.Push tag deletion: %w", err)
+	}
+	return nil
 }
 
 func (repo *Repository) Name() string {
@@ -1,19 +1,30 @@
 package gitprovider
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/duanqy/semantic-release/pkg/plugin"
+	"github.com/duanqy/semantic-release/pkg/semrel"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/stretchr/testify/require"
+	cryptossh "golang.org/x/crypto/ssh"
 )
 
 var testGitPath string
@@ -27,13 +38,41 @@ func TestGit(t *testing.T) {
 	t.Run("GetReleases", getReleases)
 	t.Run("GetCommits", getCommits)
 	t.Run("CreateRelease", createRelease)
+	t.Run("DeleteRelease", deleteRelease)
+	t.Run("TagFormat", tagFormat)
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	testCases := []struct {
+		url   string
+		owner string
+		repo  string
+	}{
+		{"https://github.com/owner/repo.git", "owner", "repo"},
+		{"https://github.com/owner/repo", "owner", "repo"},
+		{"http://localhost:3000/test/test.git", "test", "test"},
+		{"https://gitlab.com/group/subgroup/repo.git", "group/subgroup", "repo"},
+		{"ssh://git@github.com:22/owner/repo.git", "owner", "repo"},
+		{"git@github.com:owner/repo.git", "owner", "repo"},
+		{"github.com:owner/repo", "owner", "repo"},
+		{"file:///srv/git/owner/repo.git", "owner", "repo"},
+		{"/srv/git/owner/repo.git", "owner", "repo"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			owner, repo := parseRemoteURL(tc.url)
+			require.Equal(t, tc.owner, owner)
+			require.Equal(t, tc.repo, repo)
+		})
+	}
 }
 
 func newRepository(t *testing.T) {
 	require := require.New(t)
 	repo := &Repository{}
 	err := repo.Init(map[string]string{})
-	require.EqualError(err, "repository does not exist")
+	require.EqualError(err, "git.PlainOpen: repository does not exist")
+	require.ErrorIs(err, git.ErrRepositoryNotExists)
 
 	repo = &Repository{}
 	err = repo.Init(map[string]string{
@@ -58,6 +97,11 @@ func setupRepo() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	remoteDir := filepath.Join(dir, "remote", "test", "test.git")
+	if _, err = git.PlainInit(remoteDir, true); err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "local")
 	repo, err := git.PlainInit(dir, false)
 	if err != nil {
 		return "", err
@@ -65,7 +109,7 @@ func setupRepo() (string, error) {
 
 	_, err = repo.CreateRemote(&config.RemoteConfig{
 		Name: "origin",
-		URLs: []string{"http://localhost:3000/test/test.git"},
+		URLs: []string{"file://" + filepath.ToSlash(remoteDir)},
 	})
 	if err != nil {
 		return "", err
@@ -156,6 +200,26 @@ func getInfo(t *testing.T) {
 	repoInfo, err := repo.GetInfo()
 	require.NoError(err)
 	require.Equal("master", repoInfo.DefaultBranch)
+	require.Equal("test", repoInfo.Owner)
+	require.Equal("test", repoInfo.Repo)
+
+	gRepo, err := git.PlainOpen(testGitPath)
+	require.NoError(err)
+	// a checked out feature branch is not the default branch
+	require.NoError(gRepo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("new-fix"))))
+	repoInfo, err = repo.GetInfo()
+	require.NoError(gRepo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("master"))))
+	require.NoError(err)
+	require.Equal("master", repoInfo.DefaultBranch)
+
+	remoteHead := plumbing.NewRemoteHEADReferenceName("origin")
+	err = gRepo.Storer.SetReference(plumbing.NewSymbolicReference(remoteHead, plumbing.NewRemoteReferenceName("origin", "new-fix")))
+	require.NoError(err)
+	defer gRepo.Storer.RemoveReference(remoteHead) //nolint:errcheck
+
+	repoInfo, err = repo.GetInfo()
+	require.NoError(err)
+	require.Equal("new-fix", repoInfo.DefaultBranch)
 }
 
 func getCommits(t *testing.T) {
@@ -166,8 +230,14 @@ func getCommits(t *testing.T) {
 	require.NoError(err)
 	require.Len(commits, 100)
 
-	for _, c := range commits {
+	for i, c := range commits {
 		require.True(strings.HasPrefix(c.RawMessage, "feat: commit"))
+		require.Equal("test", c.Author.Name)
+		require.Equal("test@test.com", c.Author.Email)
+		require.False(c.Committer.Date.IsZero())
+		if i < len(commits)-1 {
+			require.Equal([]string{commits[i+1].SHA}, c.Parents)
+		}
 	}
 }
 
@@ -197,6 +267,28 @@ func createRelease(t *testing.T) {
 	require.Equal("new feature\n", tagObj.Message)
 }
 
+func deleteRelease(t *testing.T) {
+	require := require.New(t)
+	repo, err := createRepo()
+	require.NoError(err)
+
+	release := &plugin.CreateReleaseConfig{NewVersion: "2.0.0"}
+	require.NoError(repo.DeleteRelease(release))
+
+	gRepo, err := git.PlainOpen(testGitPath)
+	require.NoError(err)
+	_, err = gRepo.Tag("v2.0.0")
+	require.ErrorIs(err, git.ErrTagNotFound)
+
+	remote, err := git.PlainOpen(filepath.Join(filepath.Dir(testGitPath), "remote", "test", "test.git"))
+	require.NoError(err)
+	_, err = remote.Tag("v2.0.0")
+	require.ErrorIs(err, git.ErrTagNotFound)
+
+	// deleting a missing release is not an error
+	require.NoError(repo.DeleteRelease(release))
+}
+
 func getReleases(t *testing.T) {
 	require := require.New(t)
 	repo, err := createRepo()
@@ -210,3 +302,218 @@ func getReleases(t *testing.T) {
 	require.NoError(err)
 	require.Len(releases, 20)
 }
+
+func tagFormat(t *testing.T) {
+	require := require.New(t)
+	repo, err := createRepo()
+	require.NoError(err)
+
+	gRepo, err := git.PlainOpen(testGitPath)
+	require.NoError(err)
+	head, err := gRepo.Head()
+	require.NoError(err)
+
+	format, err := semrel.NewTagFormat("release-{{.Version}}")
+	require.NoError(err)
+	release := &plugin.CreateReleaseConfig{
+		NewVersion: "3.0.0",
+		Tag:        format.Tag("3.0.0"),
+		SHA:        head.Hash().String(),
+		Changelog:  "release",
+	}
+	require.NoError(repo.CreateRelease(release))
+	defer repo.DeleteRelease(release) //nolint:errcheck
+
+	_, err = gRepo.Tag("release-3.0.0")
+	require.NoError(err)
+
+	releases, err := repo.GetReleases(format.Regex(""))
+	require.NoError(err)
+	require.Len(releases, 1)
+	require.Equal("3.0.0", releases[0].Version)
+	require.Equal(head.Hash().String(), releases[0].SHA)
+
+	releases, err = repo.GetReleases("")
+	require.NoError(err)
+	require.Len(releases, 30)
+}
+
+func writeOpenPGPKeys(dir string) (string, string, error) {
+	entity, err := openpgp.NewEntity("test", "", "test@test.com", nil)
+	if err != nil {
+		return "", "", err
+	}
+	privBuf := &bytes.Buffer{}
+	w, err := armor.Encode(privBuf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err = entity.SerializePrivate(w, nil); err != nil {
+		return "", "", err
+	}
+	w.Close()
+	pubBuf := &bytes.Buffer{}
+	w, err = armor.Encode(pubBuf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err = entity.Serialize(w); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	privFile := filepath.Join(dir, "gpg.key")
+	pubFile := filepath.Join(dir, "gpg.pub")
+	if err = ioutil.WriteFile(privFile, privBuf.Bytes(), 0600); err != nil {
+		return "", "", err
+	}
+	return privFile, pubFile, ioutil.WriteFile(pubFile, pubBuf.Bytes(), 0644)
+}
+
+func writeSSHKeys(dir string) (string, string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return "", "", err
+	}
+	sshPub, err := cryptossh.NewPublicKey(pub)
+	if err != nil {
+		return "", "", err
+	}
+	privFile := filepath.Join(dir, "id_ed25519")
+	signersFile := filepath.Join(dir, "allowed_signers")
+	if err = ioutil.WriteFile(privFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return "", "", err
+	}
+	allowedSigners := "# trusted release keys\ntest@test.com namespaces=\"git\" " + string(cryptossh.MarshalAuthorizedKey(sshPub))
+	return privFile, signersFile, ioutil.WriteFile(signersFile, []byte(allowedSigners), 0644)
+}
+
+func TestSignedTags(t *testing.T) {
+	require := require.New(t)
+	gitPath, err := setupRepo()
+	require.NoError(err)
+	keyDir, err := ioutil.TempDir("", "provider-git-keys")
+	require.NoError(err)
+	gpgKey, gpgPub, err := writeOpenPGPKeys(keyDir)
+	require.NoError(err)
+	sshKey, allowedSigners, err := writeSSHKeys(keyDir)
+	require.NoError(err)
+
+	gRepo, err := git.PlainOpen(gitPath)
+	require.NoError(err)
+	head, err := gRepo.Head()
+	require.NoError(err)
+
+	repo := &Repository{}
+	require.NoError(repo.Init(map[string]string{"git_path": gitPath, "sign_gpg_key": gpgKey}))
+	require.NoError(repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "3.0.0",
+		SHA:        head.Hash().String(),
+		Changelog:  "gpg signed",
+	}))
+	tagRef, err := gRepo.Tag("v3.0.0")
+	require.NoError(err)
+	tagObj, err := gRepo.TagObject(tagRef.Hash())
+	require.NoError(err)
+	require.NotEmpty(tagObj.PGPSignature)
+
+	repo = &Repository{}
+	require.NoError(repo.Init(map[string]string{"git_path": gitPath, "sign_ssh_key": sshKey}))
+	require.NoError(repo.CreateRelease(&plugin.CreateReleaseConfig{
+		NewVersion: "3.1.0",
+		SHA:        head.Hash().String(),
+		Changelog:  "ssh signed",
+	}))
+	tagRef, err = gRepo.Tag("v3.1.0")
+	require.NoError(err)
+	tagObj, err = gRepo.TagObject(tagRef.Hash())
+	require.NoError(err)
+	require.True(strings.HasPrefix(tagObj.Message, "ssh signed\n"+beginSSHSignature))
+
+	repo = &Repository{}
+	err = repo.Init(map[string]string{"git_path": gitPath, "verify_tags": "ignore"})
+	require.EqualError(err, "verify_tags requires verify_gpg_keyring or verify_ssh_allowed_signers")
+
+	repo = &Repository{}
+	require.NoError(repo.Init(map[string]string{
+		"git_path":                   gitPath,
+		"verify_tags":                "ignore",
+		"verify_gpg_keyring":         gpgPub,
+		"verify_ssh_allowed_signers": allowedSigners,
+	}))
+	releases, err := repo.GetReleases("")
+	require.NoError(err)
+	require.Len(releases, 2)
+	versions := []string{releases[0].Version, releases[1].Version}
+	require.ElementsMatch([]string{"3.0.0", "3.1.0"}, versions)
+	for _, r := range releases {
+		require.Equal(head.Hash().String(), r.SHA)
+	}
+
+	// only the gpg key is trusted
+	repo = &Repository{}
+	require.NoError(repo.Init(map[string]string{
+		"git_path":           gitPath,
+		"verify_tags":        "ignore",
+		"verify_gpg_keyring": gpgPub,
+	}))
+	releases, err = repo.GetReleases("^v3")
+	require.NoError(err)
+	require.Len(releases, 1)
+	require.Equal("3.0.0", releases[0].Version)
+
+	repo = &Repository{}
+	require.NoError(repo.Init(map[string]string{
+		"git_path":                   gitPath,
+		"verify_tags":                "reject",
+		"verify_ssh_allowed_signers": allowedSigners,
+	}))
+	_, err = repo.GetReleases("^v3\\.1")
+	require.NoError(err)
+	_, err = repo.GetReleases("")
+	require.Error(err)
+}
+
+func TestCommitFiles(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "provider-git-files")
+	require.NoError(err)
+	gRepo, err := git.PlainInit(dir, false)
+	require.NoError(err)
+	w, err := gRepo.Worktree()
+	require.NoError(err)
+
+	author := &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()}
+	commitFiles := func(message string, files ...string) {
+		for _, f := range files {
+			require.NoError(os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755))
+			require.NoError(ioutil.WriteFile(filepath.Join(dir, f), []byte(message), 0644))
+			_, err := w.Add(f)
+			require.NoError(err)
+		}
+		_, err := w.Commit(message, &git.CommitOptions{Author: author})
+		require.NoError(err)
+	}
+	commitFiles("feat: initial", "README.md")
+	commitFiles("feat(api): endpoint", "api/main.go", "api/go.mod")
+	commitFiles("fix(web): button", "web/index.js")
+	_, err = w.Remove("api/go.mod")
+	require.NoError(err)
+	_, err = w.Commit("chore(api): drop go.mod", &git.CommitOptions{Author: author})
+	require.NoError(err)
+
+	repo := &Repository{}
+	require.NoError(repo.Init(map[string]string{"git_path": dir, "commit_files": "true"}))
+	commits, err := repo.GetCommits("", "HEAD")
+	require.NoError(err)
+	require.Len(commits, 4)
+	require.Equal([]string{"api/go.mod"}, commits[0].Files)
+	require.Equal([]string{"web/index.js"}, commits[1].Files)
+	require.ElementsMatch([]string{"api/main.go", "api/go.mod"}, commits[2].Files)
+	require.Equal([]string{"README.md"}, commits[3].Files)
+}
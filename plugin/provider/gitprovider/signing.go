@@ -0,0 +1,331 @@
+package gitprovider
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshSigMagic           = "SSHSIG"
+	sshSigVersion         = 1
+	sshSigNamespace       = "git"
+	sshSigHashAlgorithm   = "sha512"
+	beginSSHSignature     = "-----BEGIN SSH SIGNATURE-----"
+	endSSHSignature       = "-----END SSH SIGNATURE-----"
+	sshSignatureLineWidth = 70
+)
+
+const (
+	tagVerificationIgnore = "ignore"
+	tagVerificationReject = "reject"
+)
+
+var (
+	errUnsignedTag   = errors.New("tag is not signed")
+	errUntrustedTag  = errors.New("tag is not signed by a trusted key")
+	errInvalidSSHSig = errors.New("invalid ssh signature")
+)
+
+// loadOpenPGPKey reads the first entity of an armored private key file and decrypts it with the passphrase.
+func loadOpenPGPKey(file, passphrase string) (*openpgp.Entity, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entities, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, errors.New("no key found in key file")
+	}
+	entity := entities[0]
+	if entity.PrivateKey == nil {
+		return nil, errors.New("key file does not contain a private key")
+	}
+	if entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, err
+		}
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			if err := subkey.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entity, nil
+}
+
+func loadSSHSigner(file, passphrase string) (ssh.Signer, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	return ssh.ParsePrivateKey(data)
+}
+
+// parseAllowedSigners reads the public keys of an ssh allowed signers file (see ssh-keygen(1)).
+func parseAllowedSigners(data []byte) ([]ssh.PublicKey, error) {
+	keys := make([]ssh.PublicKey, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// the first field contains the principals, the rest is in authorized_keys format
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid allowed signers line: %s", line)
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fields[1]))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func appendSSHString(buf *bytes.Buffer, data []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+func readSSHString(data []byte) ([]byte, []byte, bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+	length := binary.BigEndian.Uint32(data)
+	if uint32(len(data)-4) < length {
+		return nil, nil, false
+	}
+	return data[4 : 4+length], data[4+length:], true
+}
+
+func newSSHSigHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha512":
+		return sha512.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported ssh signature hash algorithm: %s", algorithm)
+}
+
+// sshSignedData builds the blob that is actually signed as described in PROTOCOL.sshsig.
+func sshSignedData(namespace, hashAlgorithm string, message []byte) ([]byte, error) {
+	h, err := newSSHSigHash(hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(message)
+	buf := &bytes.Buffer{}
+	buf.WriteString(sshSigMagic)
+	appendSSHString(buf, []byte(namespace))
+	appendSSHString(buf, nil)
+	appendSSHString(buf, []byte(hashAlgorithm))
+	appendSSHString(buf, h.Sum(nil))
+	return buf.Bytes(), nil
+}
+
+// sshSign creates an armored ssh signature of message in the git namespace, the same way `ssh-keygen -Y sign` does.
+func sshSign(signer ssh.Signer, message []byte) (string, error) {
+	signedData, err := sshSignedData(sshSigNamespace, sshSigHashAlgorithm, message)
+	if err != nil {
+		return "", err
+	}
+	var sig *ssh.Signature
+	// RSA keys have to use a SHA-2 signature algorithm
+	if algSigner, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = algSigner.SignWithAlgorithm(rand.Reader, signedData, ssh.SigAlgoRSASHA2512)
+	} else {
+		sig, err = signer.Sign(rand.Reader, signedData)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(sshSigMagic)
+	_ = binary.Write(buf, binary.BigEndian, uint32(sshSigVersion))
+	appendSSHString(buf, signer.PublicKey().Marshal())
+	appendSSHString(buf, []byte(sshSigNamespace))
+	appendSSHString(buf, nil)
+	appendSSHString(buf, []byte(sshSigHashAlgorithm))
+	appendSSHString(buf, ssh.Marshal(sig))
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	armored := beginSSHSignature + "\n"
+	for len(encoded) > sshSignatureLineWidth {
+		armored += encoded[:sshSignatureLineWidth] + "\n"
+		encoded = encoded[sshSignatureLineWidth:]
+	}
+	armored += encoded + "\n" + endSSHSignature + "\n"
+	return armored, nil
+}
+
+// sshVerify checks that the armored signature of message was created in the git namespace by one of the allowed keys.
+func sshVerify(allowedKeys []ssh.PublicKey, message []byte, armored string) error {
+	armored = strings.TrimSpace(armored)
+	if !strings.HasPrefix(armored, beginSSHSignature) || !strings.HasSuffix(armored, endSSHSignature) {
+		return errInvalidSSHSig
+	}
+	encoded := strings.TrimSuffix(strings.TrimPrefix(armored, beginSSHSignature), endSSHSignature)
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return errInvalidSSHSig
+	}
+	if !bytes.HasPrefix(data, []byte(sshSigMagic)) || len(data) < len(sshSigMagic)+4 {
+		return errInvalidSSHSig
+	}
+	data = data[len(sshSigMagic):]
+	if binary.BigEndian.Uint32(data) != sshSigVersion {
+		return errInvalidSSHSig
+	}
+	data = data[4:]
+
+	fields := make([][]byte, 5)
+	for i := range fields {
+		var ok bool
+		if fields[i], data, ok = readSSHString(data); !ok {
+			return errInvalidSSHSig
+		}
+	}
+	rawKey, namespace, hashAlgorithm, rawSig := fields[0], string(fields[1]), string(fields[3]), fields[4]
+	if namespace != sshSigNamespace {
+		return fmt.Errorf("unexpected ssh signature namespace: %s", namespace)
+	}
+
+	trusted := false
+	for _, key := range allowedKeys {
+		if bytes.Equal(key.Marshal(), rawKey) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return errUntrustedTag
+	}
+
+	pubKey, err := ssh.ParsePublicKey(rawKey)
+	if err != nil {
+		return err
+	}
+	sig := &ssh.Signature{}
+	if err := ssh.Unmarshal(rawSig, sig); err != nil {
+		return errInvalidSSHSig
+	}
+	signedData, err := sshSignedData(namespace, hashAlgorithm, message)
+	if err != nil {
+		return err
+	}
+	return pubKey.Verify(signedData, sig)
+}
+
+// createSSHSignedTag creates an annotated tag object that carries an ssh signature as git does with gpg.format=ssh.
+func (repo *Repository) createSSHSignedTag(name string, target plumbing.Hash, message string, tagger *object.Signature) error {
+	if strings.TrimSpace(message) == "" {
+		return errors.New("message field is required")
+	}
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *tagger,
+		Message:    strings.TrimSpace(message) + "\n",
+		TargetType: plumbing.CommitObject,
+		Target:     target,
+	}
+	payload := repo.repo.Storer.NewEncodedObject()
+	if err := tag.EncodeWithoutSignature(payload); err != nil {
+		return err
+	}
+	reader, err := payload.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	payloadData, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	sig, err := sshSign(repo.sshSigner, payloadData)
+	if err != nil {
+		return err
+	}
+	// git stores ssh signatures the same way as gpg signatures, appended to the message
+	tag.PGPSignature = sig
+
+	obj := repo.repo.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		return err
+	}
+	hash, err := repo.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return err
+	}
+	return repo.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), hash))
+}
+
+// verifyTag checks the signature of an annotated tag against the configured trusted keys.
+func (repo *Repository) verifyTag(tagObj *object.Tag) error {
+	if tagObj == nil {
+		return errUnsignedTag
+	}
+	if tagObj.PGPSignature != "" {
+		if repo.verifyKeyRing == "" {
+			return errUntrustedTag
+		}
+		if _, err := tagObj.Verify(repo.verifyKeyRing); err != nil {
+			return fmt.Errorf("%w: %s", errUntrustedTag, err)
+		}
+		return nil
+	}
+
+	// go-git only detects gpg signatures, ssh signatures remain part of the message
+	idx := strings.Index(tagObj.Message, beginSSHSignature)
+	if idx < 0 {
+		return errUnsignedTag
+	}
+	unsigned := *tagObj
+	unsigned.Message = tagObj.Message[:idx]
+	payload := &plumbing.MemoryObject{}
+	if err := unsigned.EncodeWithoutSignature(payload); err != nil {
+		return err
+	}
+	reader, err := payload.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	payloadData, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if err := sshVerify(repo.allowedSigners, payloadData, tagObj.Message[idx:]); err != nil {
+		if errors.Is(err, errUntrustedTag) {
+			return err
+		}
+		return fmt.Errorf("%w: %s", errUntrustedTag, err)
+	}
+	return nil
+}